@@ -0,0 +1,197 @@
+package admin
+
+import (
+	stderrors "errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"gin-app/errors"
+	"gin-app/handler"
+	"gin-app/log"
+	"gin-app/models"
+	"gin-app/responses"
+	"gin-app/security"
+)
+
+// SystemAdminPolicy guards every admin route
+var SystemAdminPolicy = security.Require(models.PermissionSystemAdmin)
+
+// auditLog records changes made through the admin API
+var auditLog = log.Component("admin")
+
+// AdminHandler handles operational requests against the running instance
+type AdminHandler struct{}
+
+// NewAdminHandler creates a new AdminHandler
+func NewAdminHandler() *AdminHandler {
+	return &AdminHandler{}
+}
+
+// LogLevelRequest represents the request body for changing a log level
+type LogLevelRequest struct {
+	Level string `json:"level" binding:"required,oneof=trace debug info warn warning error fatal panic"`
+	// TTL is a duration such as "15m" after which the configured level is
+	// restored. Empty keeps the level until it is reset
+	TTL string `json:"ttl"`
+}
+
+// LogLevelResponse describes the log level of the global logger or a component
+type LogLevelResponse struct {
+	Component string     `json:"component,omitempty"`
+	Level     string     `json:"level"`
+	Override  bool       `json:"override"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// RegisterRoutes registers all admin routes behind authMiddleware and SystemAdminPolicy.
+// Routes without a component act on the global level of log.Logger
+func (h *AdminHandler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	admin := router.Group("/admin", authMiddleware, handler.Authorize(SystemAdminPolicy))
+	{
+		admin.GET("/log-level", h.GetLogLevels)
+		admin.PUT("/log-level", h.SetLogLevel)
+		admin.DELETE("/log-level", h.ResetLogLevel)
+		admin.GET("/log-level/:component", h.GetLogLevel)
+		admin.PUT("/log-level/:component", h.SetLogLevel)
+		admin.DELETE("/log-level/:component", h.ResetLogLevel)
+	}
+}
+
+// GetLogLevels lists the global log level and the level of every component
+// @Summary List log levels
+// @Description Get the global log level and the level of every named component logger
+// @Tags admin
+// @Produce json
+// @Success 200 {object} responses.Response
+// @Failure 401 {object} responses.Response
+// @Failure 403 {object} responses.Response
+// @Router /api/v1/admin/log-level [get]
+func (h *AdminHandler) GetLogLevels(c *gin.Context) {
+	statuses := log.Levels()
+	levels := make([]LogLevelResponse, 0, len(statuses))
+	for _, status := range statuses {
+		levels = append(levels, newLogLevelResponse(status))
+	}
+	responses.Success(c, "admin.log_levels_retrieved", levels)
+}
+
+// GetLogLevel retrieves the log level of a component
+// @Summary Get a component's log level
+// @Tags admin
+// @Produce json
+// @Param component path string true "Component name"
+// @Success 200 {object} responses.Response
+// @Failure 401 {object} responses.Response
+// @Failure 403 {object} responses.Response
+// @Failure 404 {object} responses.Response
+// @Router /api/v1/admin/log-level/{component} [get]
+func (h *AdminHandler) GetLogLevel(c *gin.Context) {
+	status, err := log.GetLevel(c.Param("component"))
+	if err != nil {
+		levelFailed(c, err)
+		return
+	}
+	responses.Success(c, "admin.log_level_retrieved", newLogLevelResponse(status))
+}
+
+// SetLogLevel overrides the global or a component's log level, optionally for a limited time
+// @Summary Set a log level
+// @Description Override the global level (/admin/log-level) or a component's level until reset or until ttl elapses
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param component path string false "Component name"
+// @Param level body LogLevelRequest true "Level and optional TTL"
+// @Success 200 {object} responses.Response
+// @Failure 400 {object} responses.Response
+// @Failure 401 {object} responses.Response
+// @Failure 403 {object} responses.Response
+// @Failure 404 {object} responses.Response
+// @Router /api/v1/admin/log-level/{component} [put]
+func (h *AdminHandler) SetLogLevel(c *gin.Context) {
+	var req LogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.BindingError(err))
+		return
+	}
+
+	var ttl time.Duration
+	if req.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(req.TTL); err != nil || ttl <= 0 {
+			c.Error(errors.ValidationError("ttl", "validation.duration"))
+			return
+		}
+	}
+
+	// The binding rules only accept names logrus can parse
+	level, _ := logrus.ParseLevel(req.Level)
+	status, err := log.SetLevel(c.Param("component"), level, ttl)
+	if err != nil {
+		levelFailed(c, err)
+		return
+	}
+
+	entry := auditLog.WithFields(logrus.Fields{"target": targetName(status), "level": level.String()})
+	if identity, ok := security.CurrentIdentity(c); ok {
+		entry = entry.WithField("by", identity.Username)
+	}
+	if ttl > 0 {
+		entry = entry.WithField("ttl", ttl.String())
+	}
+	entry.Warn("log level overridden")
+
+	responses.Success(c, "admin.log_level_updated", newLogLevelResponse(status))
+}
+
+// ResetLogLevel removes an override and restores the configured log level
+// @Summary Reset a log level
+// @Tags admin
+// @Produce json
+// @Param component path string false "Component name"
+// @Success 200 {object} responses.Response
+// @Failure 401 {object} responses.Response
+// @Failure 403 {object} responses.Response
+// @Failure 404 {object} responses.Response
+// @Router /api/v1/admin/log-level/{component} [delete]
+func (h *AdminHandler) ResetLogLevel(c *gin.Context) {
+	status, err := log.ResetLevel(c.Param("component"))
+	if err != nil {
+		levelFailed(c, err)
+		return
+	}
+
+	auditLog.WithField("target", targetName(status)).Info("log level override removed")
+	responses.Success(c, "admin.log_level_reset", newLogLevelResponse(status))
+}
+
+// levelFailed reports an error from the log level registry
+func levelFailed(c *gin.Context, err error) {
+	if stderrors.Is(err, log.ErrUnknownComponent) {
+		c.Error(errors.NotFound("admin.unknown_component", map[string]string{"component": c.Param("component")}))
+		return
+	}
+	c.Error(err)
+}
+
+func newLogLevelResponse(status log.LevelStatus) LogLevelResponse {
+	resp := LogLevelResponse{
+		Component: status.Component,
+		Level:     status.Level.String(),
+		Override:  status.Override,
+	}
+	if !status.ExpiresAt.IsZero() {
+		expiresAt := status.ExpiresAt
+		resp.ExpiresAt = &expiresAt
+	}
+	return resp
+}
+
+func targetName(status log.LevelStatus) string {
+	if status.Component == "" {
+		return "global"
+	}
+	return status.Component
+}
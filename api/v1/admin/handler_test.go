@@ -0,0 +1,98 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	apperrors "gin-app/errors"
+	"gin-app/handler"
+	"gin-app/log"
+	"gin-app/models"
+	"gin-app/security"
+)
+
+func setupAdminRouter(roles ...string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	authenticate := func(c *gin.Context) {
+		security.SetIdentity(c, &security.Identity{UserID: "1", Username: "root", Roles: roles})
+	}
+
+	router := gin.New()
+	router.Use(handler.ErrorHandlerMiddleware(apperrors.NewMapper()))
+	NewAdminHandler().RegisterRoutes(router.Group("/api/v1"), authenticate)
+	return router
+}
+
+func send(router *gin.Engine, method, path, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	var decoded map[string]interface{}
+	json.Unmarshal(resp.Body.Bytes(), &decoded)
+	return resp, decoded
+}
+
+func TestLogLevelEndpoints(t *testing.T) {
+	router := setupAdminRouter(models.RoleAdmin)
+	log.Component("payments")
+	defer log.ResetLevel("")
+	defer log.ResetLevel("payments")
+
+	resp, body := send(router, "PUT", "/api/v1/admin/log-level/payments", `{"level":"debug","ttl":"15m"}`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	data := body["data"].(map[string]interface{})
+	assert.Equal(t, "payments", data["component"])
+	assert.Equal(t, "debug", data["level"])
+	assert.Equal(t, true, data["override"])
+	assert.NotEmpty(t, data["expires_at"])
+	assert.Equal(t, logrus.DebugLevel, log.Logger.GetLevel())
+
+	resp, body = send(router, "GET", "/api/v1/admin/log-level/payments", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "debug", body["data"].(map[string]interface{})["level"])
+
+	resp, body = send(router, "PUT", "/api/v1/admin/log-level", `{"level":"warn"}`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	data = body["data"].(map[string]interface{})
+	assert.Nil(t, data["component"])
+	assert.Equal(t, "warning", data["level"])
+	assert.Nil(t, data["expires_at"])
+
+	resp, body = send(router, "GET", "/api/v1/admin/log-level", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	levels := body["data"].([]interface{})
+	assert.Equal(t, "warning", levels[0].(map[string]interface{})["level"])
+
+	resp, body = send(router, "DELETE", "/api/v1/admin/log-level/payments", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	data = body["data"].(map[string]interface{})
+	assert.Equal(t, false, data["override"])
+	assert.Equal(t, "warning", data["level"]) // follows the global override
+}
+
+func TestLogLevelErrors(t *testing.T) {
+	router := setupAdminRouter(models.RoleAdmin)
+
+	resp, _ := send(router, "PUT", "/api/v1/admin/log-level/nope", `{"level":"debug"}`)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp, _ = send(router, "PUT", "/api/v1/admin/log-level", `{"level":"verbose"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp, body := send(router, "PUT", "/api/v1/admin/log-level", `{"level":"debug","ttl":"soon"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, body["data"], "ttl")
+	assert.NotEqual(t, logrus.DebugLevel, log.Logger.GetLevel())
+
+	resp, _ = send(setupAdminRouter(models.RoleUser), "GET", "/api/v1/admin/log-level", "")
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+}
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	stderrors "errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"gin-app/errors"
+	"gin-app/log"
+	"gin-app/metrics"
+	"gin-app/models"
+	"gin-app/responses"
+	"gin-app/security"
+)
+
+// AuthHandler handles login, token refresh and logout
+type AuthHandler struct {
+	userRepo models.UserRepository
+	tokens   *security.TokenService
+}
+
+// NewAuthHandler creates a new AuthHandler
+func NewAuthHandler(userRepo models.UserRepository, tokens *security.TokenService) *AuthHandler {
+	return &AuthHandler{
+		userRepo: userRepo,
+		tokens:   tokens,
+	}
+}
+
+// LoginRequest represents the request body for logging in
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// RefreshRequest represents the request body for refreshing tokens
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// LogoutRequest represents the optional request body for logging out
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RegisterRoutes registers all auth-related routes. authMiddleware protects logout
+func (h *AuthHandler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	auth := router.Group("/auth")
+	{
+		auth.POST("/login", h.Login)
+		auth.POST("/refresh", h.Refresh)
+		auth.POST("/logout", authMiddleware, h.Logout)
+	}
+}
+
+// Login verifies credentials and issues a token pair
+// @Summary Log in
+// @Description Exchange username and password for an access and refresh token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body LoginRequest true "Credentials"
+// @Success 200 {object} responses.Response
+// @Failure 400 {object} responses.Response
+// @Failure 401 {object} responses.Response
+// @Failure 500 {object} responses.Response
+// @Router /api/v1/auth/login [post]
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.BindingError(err))
+		return
+	}
+
+	// Failed logins are attributed in the access log
+	log.AddFields(c, logrus.Fields{"username": req.Username})
+
+	user, err := models.Authenticate(models.TracedUserRepository(c.Request.Context(), h.userRepo), req.Username, req.Password)
+	if err != nil {
+		metrics.Logins.WithLabelValues("failure").Inc()
+		c.Error(err)
+		return
+	}
+	metrics.Logins.WithLabelValues("success").Inc()
+
+	pair, err := h.tokens.Issue(user)
+	if err != nil {
+		log.FromContext(c).WithError(err).Error("failed to issue tokens")
+		c.Error(errors.NewLocalizedError(http.StatusInternalServerError, "auth.issue_failed"))
+		return
+	}
+
+	responses.Success(c, "auth.login_succeeded", pair)
+}
+
+// Refresh rotates a refresh token into a new token pair
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new access and refresh token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token body RefreshRequest true "Refresh token"
+// @Success 200 {object} responses.Response
+// @Failure 400 {object} responses.Response
+// @Failure 401 {object} responses.Response
+// @Router /api/v1/auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.BindingError(err))
+		return
+	}
+
+	pair, err := h.tokens.Refresh(req.RefreshToken)
+	if err != nil {
+		message := "auth.invalid_refresh_token"
+		if stderrors.Is(err, security.ErrRefreshTokenReused) {
+			message = "auth.refresh_token_reused"
+		}
+		c.Error(errors.Unauthorized(message, nil))
+		return
+	}
+
+	responses.Success(c, "auth.refreshed", pair)
+}
+
+// Logout revokes the caller's access token and optionally its refresh token
+// @Summary Log out
+// @Description Revoke the current access token and, if provided, the refresh token
+// @Tags auth
+// @Accept json
+// @Param token body LogoutRequest false "Refresh token"
+// @Success 204 "No Content"
+// @Failure 401 {object} responses.Response
+// @Failure 500 {object} responses.Response
+// @Router /api/v1/auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	identity, ok := security.CurrentIdentity(c)
+	if !ok {
+		c.Error(errors.Unauthorized("", nil))
+		return
+	}
+
+	// The body is optional; an empty or invalid body only revokes the access token
+	var req LogoutRequest
+	_ = c.ShouldBindJSON(&req)
+
+	if err := h.tokens.Revoke(identity, req.RefreshToken); err != nil {
+		log.FromContext(c).WithError(err).Error("failed to revoke tokens")
+		c.Error(errors.NewLocalizedError(http.StatusInternalServerError, "auth.revoke_failed"))
+		return
+	}
+
+	responses.NoContent(c)
+}
@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+
+	apperrors "gin-app/errors"
+	"gin-app/handler"
+	"gin-app/models"
+	"gin-app/security"
+)
+
+const testPassword = "correct-horse-battery"
+
+// setupAuthRouter wires the auth routes the way the application does, with
+// one registered user
+func setupAuthRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	models.SetPasswordHasher(models.NewBcryptHasher(bcrypt.MinCost))
+
+	repo := models.NewInMemoryUserRepository()
+	user := &models.User{ID: "1", Username: "testuser", Email: "test@example.com"}
+	assert.Nil(t, user.SetPassword(testPassword))
+	assert.Nil(t, repo.Create(user))
+
+	tokens := security.NewTokenService(security.TokenOptions{
+		Secret:          []byte("test-secret"),
+		Issuer:          "gin-app-test",
+		AccessTokenTTL:  time.Minute,
+		RefreshTokenTTL: time.Hour,
+	}, security.NewInMemoryRefreshTokenStore(), repo)
+
+	mapper := apperrors.NewMapper()
+	models.RegisterErrorMappings(mapper)
+
+	router := gin.New()
+	router.Use(handler.ErrorHandlerMiddleware(mapper))
+	NewAuthHandler(repo, tokens).RegisterRoutes(router.Group("/api/v1"), handler.AuthMiddleware(tokens))
+	return router
+}
+
+// post sends a JSON body, with a bearer token if one is given
+func post(router *gin.Engine, path, body, accessToken string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+// tokenPair decodes the token pair from a successful login or refresh
+func tokenPair(t *testing.T, resp *httptest.ResponseRecorder) security.TokenPair {
+	var body struct {
+		Data security.TokenPair `json:"data"`
+	}
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &body))
+	assert.NotEmpty(t, body.Data.AccessToken)
+	assert.NotEmpty(t, body.Data.RefreshToken)
+	return body.Data
+}
+
+func login(t *testing.T, router *gin.Engine) security.TokenPair {
+	resp := post(router, "/api/v1/auth/login", `{"username":"testuser","password":"`+testPassword+`"}`, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	return tokenPair(t, resp)
+}
+
+func refresh(router *gin.Engine, refreshToken string) *httptest.ResponseRecorder {
+	return post(router, "/api/v1/auth/refresh", `{"refresh_token":"`+refreshToken+`"}`, "")
+}
+
+func TestLogin(t *testing.T) {
+	router := setupAuthRouter(t)
+
+	// Wrong password and unknown user are indistinguishable
+	for _, body := range []string{
+		`{"username":"testuser","password":"wrong"}`,
+		`{"username":"nobody","password":"` + testPassword + `"}`,
+	} {
+		resp := post(router, "/api/v1/auth/login", body, "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.Contains(t, resp.Header().Get("WWW-Authenticate"), "Bearer")
+		assert.Contains(t, resp.Body.String(), "Invalid username or password")
+	}
+
+	// Missing password
+	resp := post(router, "/api/v1/auth/login", `{"username":"testuser"}`, "")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	pair := login(t, router)
+	assert.Equal(t, "Bearer", pair.TokenType)
+	assert.Equal(t, int64(60), pair.ExpiresIn)
+}
+
+func TestRefreshRotatesTokens(t *testing.T) {
+	router := setupAuthRouter(t)
+	first := login(t, router)
+
+	resp := refresh(router, first.RefreshToken)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	second := tokenPair(t, resp)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+
+	// The rotated token keeps working
+	resp = refresh(router, second.RefreshToken)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = refresh(router, "not-a-refresh-token")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Contains(t, resp.Header().Get("WWW-Authenticate"), "Bearer")
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	router := setupAuthRouter(t)
+	first := login(t, router)
+
+	resp := refresh(router, first.RefreshToken)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	second := tokenPair(t, resp)
+
+	// Replaying the rotated token is rejected...
+	resp = refresh(router, first.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Contains(t, resp.Body.String(), "already been used")
+
+	// ...and revokes the token issued from it as well
+	resp = refresh(router, second.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	// Other logins are not affected
+	other := login(t, router)
+	assert.Equal(t, http.StatusOK, refresh(router, other.RefreshToken).Code)
+}
+
+func TestLogout(t *testing.T) {
+	router := setupAuthRouter(t)
+	pair := login(t, router)
+
+	// Logout requires an access token
+	resp := post(router, "/api/v1/auth/logout", `{"refresh_token":"`+pair.RefreshToken+`"}`, "")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	resp = post(router, "/api/v1/auth/logout", `{"refresh_token":"`+pair.RefreshToken+`"}`, pair.AccessToken)
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+
+	// Both the refresh token and the access token are revoked
+	resp = refresh(router, pair.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	resp = post(router, "/api/v1/auth/logout", "", pair.AccessToken)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+}
@@ -0,0 +1,237 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"gin-app/responses"
+)
+
+// Check statuses reported by /livez and /readyz
+const (
+	StatusFail         = "fail"
+	StatusShuttingDown = "shutting_down"
+)
+
+// Checker reports whether a dependency the service relies on is usable
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type checkerFunc struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// NewChecker creates a Checker from a function, e.g.
+// health.NewChecker("database", db.PingContext)
+func NewChecker(name string, check func(ctx context.Context) error) Checker {
+	return checkerFunc{name: name, check: check}
+}
+
+func (f checkerFunc) Name() string                    { return f.name }
+func (f checkerFunc) Check(ctx context.Context) error { return f.check(ctx) }
+
+// CheckOptions controls how a registered Checker is run. Zero durations use
+// the registry defaults
+type CheckOptions struct {
+	// Liveness adds the check to /livez instead of /readyz. Only checks whose
+	// failure means the process must be restarted belong there
+	Liveness bool
+	// Timeout bounds a single run of the check
+	Timeout time.Duration
+	// Interval is how long a result is reused before the check runs again
+	Interval time.Duration
+}
+
+// Result is the outcome of a single check
+type Result struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Latency   string    `json:"latency"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Report is the overall status and the result of every check of a probe
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// Healthy reports whether every check passed
+func (r Report) Healthy() bool {
+	return r.Status == StatusOK
+}
+
+// check is a registered Checker with its cached result
+type check struct {
+	checker  Checker
+	timeout  time.Duration
+	interval time.Duration
+
+	mu     sync.Mutex // serializes runs, so concurrent probes share one result
+	result Result
+	ran    time.Time
+}
+
+// Registry holds the liveness and readiness checks of the service
+type Registry struct {
+	timeout  time.Duration
+	interval time.Duration
+
+	mu           sync.RWMutex
+	liveness     []*check
+	readiness    []*check
+	shuttingDown atomic.Bool
+}
+
+// NewRegistry creates a Registry whose checks time out after timeout and cache
+// their results for interval unless CheckOptions says otherwise
+func NewRegistry(timeout, interval time.Duration) *Registry {
+	return &Registry{timeout: timeout, interval: interval}
+}
+
+// Register adds checker to the readiness checks, or to the liveness checks
+// when opts.Liveness is set
+func (r *Registry) Register(checker Checker, opts CheckOptions) {
+	c := &check{checker: checker, timeout: opts.Timeout, interval: opts.Interval}
+	if c.timeout <= 0 {
+		c.timeout = r.timeout
+	}
+	if c.interval <= 0 {
+		c.interval = r.interval
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if opts.Liveness {
+		r.liveness = append(r.liveness, c)
+	} else {
+		r.readiness = append(r.readiness, c)
+	}
+}
+
+// SetShuttingDown makes readiness fail from now on so that load balancers stop
+// sending new requests while in-flight ones finish. Liveness is unaffected
+func (r *Registry) SetShuttingDown() {
+	r.shuttingDown.Store(true)
+}
+
+// Live runs the liveness checks
+func (r *Registry) Live(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := r.liveness
+	r.mu.RUnlock()
+	return run(ctx, checks)
+}
+
+// Ready runs the readiness checks. During shutdown it fails without running them
+func (r *Registry) Ready(ctx context.Context) Report {
+	if r.shuttingDown.Load() {
+		return Report{Status: StatusShuttingDown, Checks: []Result{}}
+	}
+	r.mu.RLock()
+	checks := r.readiness
+	r.mu.RUnlock()
+	return run(ctx, checks)
+}
+
+// Livez handles the liveness probe: 200 while the process works, 503 otherwise
+func (r *Registry) Livez(c *gin.Context) {
+	respond(c, r.Live(c.Request.Context()), "health.live", "health.not_live")
+}
+
+// Readyz handles the readiness probe: 200 when the service can take traffic,
+// 503 when a dependency is down or the server is shutting down
+func (r *Registry) Readyz(c *gin.Context) {
+	respond(c, r.Ready(c.Request.Context()), "health.ready", "health.not_ready")
+}
+
+// Health handles the legacy /ping and /api/v1/health endpoints. It follows
+// readiness, answering 503 with the report when a check fails or the server
+// is shutting down; the healthy response is unchanged
+func (r *Registry) Health(c *gin.Context) {
+	report := r.Ready(c.Request.Context())
+	if report.Healthy() {
+		responses.Success(c, "health.healthy", nil)
+		return
+	}
+	responses.Error(c, http.StatusServiceUnavailable, "health.unhealthy", report)
+}
+
+func respond(c *gin.Context, report Report, ok, failed string) {
+	if report.Healthy() {
+		responses.Success(c, ok, report)
+		return
+	}
+	responses.Error(c, http.StatusServiceUnavailable, failed, report)
+}
+
+// run runs checks concurrently and collects their results in registration order
+func run(ctx context.Context, checks []*check) Report {
+	report := Report{Status: StatusOK, Checks: make([]Result, len(checks))}
+
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c *check) {
+			defer wg.Done()
+			report.Checks[i] = c.run(ctx)
+		}(i, c)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+// run returns the cached result if it is recent enough, otherwise runs the check
+func (c *check) run(ctx context.Context) Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.ran.IsZero() && time.Since(c.ran) < c.interval {
+		return c.result
+	}
+
+	// The result is shared with other probes, so a client disconnecting must
+	// not cancel the check; only the timeout does
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	// A checker that ignores ctx must not block the probe past its timeout
+	done := make(chan error, 1)
+	go func() { done <- c.checker.Check(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s", c.timeout)
+	}
+
+	c.result = Result{
+		Name:      c.checker.Name(),
+		Status:    StatusOK,
+		Latency:   time.Since(start).String(),
+		CheckedAt: start,
+	}
+	if err != nil {
+		c.result.Status = StatusFail
+		c.result.Error = err.Error()
+	}
+	c.ran = time.Now()
+	return c.result
+}
@@ -0,0 +1,105 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func probe(registry *Registry, path string) (int, Report) {
+	router := gin.New()
+	router.GET("/livez", registry.Livez)
+	router.GET("/readyz", registry.Readyz)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", path, nil)
+	router.ServeHTTP(w, req)
+
+	var body struct {
+		Data Report `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	return w.Code, body.Data
+}
+
+func TestReadyz(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registry := NewRegistry(time.Second, 0)
+	var dbErr error
+	registry.Register(NewChecker("database", func(ctx context.Context) error { return dbErr }), CheckOptions{})
+	registry.Register(NewChecker("cache", func(ctx context.Context) error { return nil }), CheckOptions{})
+
+	code, report := probe(registry, "/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, StatusOK, report.Status)
+	if assert.Len(t, report.Checks, 2) {
+		assert.Equal(t, "database", report.Checks[0].Name)
+		assert.Equal(t, StatusOK, report.Checks[0].Status)
+		assert.NotEmpty(t, report.Checks[0].Latency)
+	}
+
+	dbErr = errors.New("connection refused")
+	code, report = probe(registry, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, StatusFail, report.Checks[0].Status)
+	assert.Equal(t, "connection refused", report.Checks[0].Error)
+	assert.Equal(t, StatusOK, report.Checks[1].Status)
+
+	// Readiness checks do not affect liveness
+	code, report = probe(registry, "/livez")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, report.Checks)
+}
+
+func TestCheckTimeout(t *testing.T) {
+	registry := NewRegistry(time.Second, 0)
+	block := make(chan struct{})
+	defer close(block)
+	// The checker ignores its context
+	registry.Register(NewChecker("stuck", func(ctx context.Context) error { <-block; return nil }),
+		CheckOptions{Liveness: true, Timeout: 20 * time.Millisecond})
+
+	start := time.Now()
+	report := registry.Live(context.Background())
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, "timed out after 20ms", report.Checks[0].Error)
+}
+
+func TestCheckResultsAreCached(t *testing.T) {
+	registry := NewRegistry(time.Second, time.Hour)
+	var runs atomic.Int32
+	registry.Register(NewChecker("counted", func(ctx context.Context) error { runs.Add(1); return nil }), CheckOptions{})
+	registry.Register(NewChecker("uncached", func(ctx context.Context) error { runs.Add(10); return nil }),
+		CheckOptions{Interval: time.Nanosecond})
+
+	first := registry.Ready(context.Background())
+	time.Sleep(time.Millisecond)
+	second := registry.Ready(context.Background())
+	assert.Equal(t, int32(21), runs.Load())
+	assert.Equal(t, first.Checks[0].CheckedAt, second.Checks[0].CheckedAt)
+	assert.NotEqual(t, first.Checks[1].CheckedAt, second.Checks[1].CheckedAt)
+}
+
+func TestReadyzDuringShutdown(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registry := NewRegistry(time.Second, 0)
+	registry.Register(NewChecker("database", func(ctx context.Context) error { return nil }), CheckOptions{})
+
+	registry.SetShuttingDown()
+	code, report := probe(registry, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, StatusShuttingDown, report.Status)
+
+	code, _ = probe(registry, "/livez")
+	assert.Equal(t, http.StatusOK, code)
+}
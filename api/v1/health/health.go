@@ -6,17 +6,19 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"gin-app/buildinfo"
 	"gin-app/responses"
 )
 
 // Info holds information about the application status
 type Info struct {
-	Status    string    `json:"status"`
-	Version   string    `json:"version"`
-	Timestamp time.Time `json:"timestamp"`
-	GoVersion string    `json:"go_version"`
-	Memory    Memory    `json:"memory"`
-	Uptime    string    `json:"uptime"`
+	Status    string         `json:"status"`
+	Version   string         `json:"version"`
+	Timestamp time.Time      `json:"timestamp"`
+	GoVersion string         `json:"go_version"`
+	Memory    Memory         `json:"memory"`
+	Uptime    string         `json:"uptime"`
+	Build     buildinfo.Info `json:"build"`
 }
 
 // Memory represents runtime memory stats
@@ -29,31 +31,29 @@ type Memory struct {
 
 var startTime = time.Now()
 
-// Constants for application info
-const (
-	StatusOK = "ok"
-	Version  = "1.0.0" // Update this with your app version
-)
+// StatusOK is reported when the application and all checks are healthy
+const StatusOK = "ok"
 
-// RegisterRoutes registers the health check routes
-func RegisterRoutes(router *gin.RouterGroup) {
-	router.GET("/health", Health)
+// RegisterRoutes registers the health check routes. /health reports the
+// readiness of checks
+func RegisterRoutes(router *gin.RouterGroup, checks *Registry) {
+	router.GET("/health", checks.Health)
 	router.GET("/status", Status)
 }
 
-// Health handles the health check endpoint
-func Health(c *gin.Context) {
-	responses.Success(c, "Service is healthy", nil)
-}
-
 // Status provides detailed information about the application status
 func Status(c *gin.Context) {
 	var m runtime.MemStats
 	runtime.ReadMemStats(&m)
 
+	// Dependency versions are left out of this unauthenticated endpoint;
+	// `gin-app version` lists them
+	build := buildinfo.Get()
+	build.Deps = nil
+
 	info := Info{
 		Status:    StatusOK,
-		Version:   Version,
+		Version:   build.Version,
 		Timestamp: time.Now(),
 		GoVersion: runtime.Version(),
 		Memory: Memory{
@@ -63,6 +63,7 @@ func Status(c *gin.Context) {
 			NumGC:      m.NumGC,
 		},
 		Uptime: time.Since(startTime).String(),
+		Build:  build,
 	}
-	responses.Success(c, "Application status", info)
+	responses.Success(c, "health.status", info)
 }
@@ -1,18 +1,27 @@
 package health
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+
+	"gin-app/buildinfo"
 )
 
 func TestHealth(t *testing.T) {
+	checks := NewRegistry(time.Second, 0)
+	var dbErr error
+	checks.Register(NewChecker("database", func(ctx context.Context) error { return dbErr }), CheckOptions{})
+
 	router := gin.Default()
-	router.GET("/health", Health)
+	router.GET("/health", checks.Health)
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", "/health", nil)
@@ -20,6 +29,22 @@ func TestHealth(t *testing.T) {
 
 	assert.Equal(t, 200, w.Code)
 	assert.Equal(t, "{\"code\":200,\"message\":\"Service is healthy\"}", w.Body.String())
+
+	// A failing dependency makes the service unhealthy
+	dbErr = errors.New("connection refused")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "Service is unhealthy")
+	assert.Contains(t, w.Body.String(), "connection refused")
+
+	// So does shutting down, even with healthy dependencies
+	dbErr = nil
+	checks.SetShuttingDown()
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), StatusShuttingDown)
 }
 
 func TestStatus(t *testing.T) {
@@ -37,4 +62,10 @@ func TestStatus(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "code")
 	assert.Contains(t, w.Body.String(), "message")
 	assert.Contains(t, w.Body.String(), "data")
+
+	// Build information comes from buildinfo, without the dependency list
+	build := actualResponse["data"].(map[string]interface{})["build"].(map[string]interface{})
+	assert.Equal(t, buildinfo.Version(), build["version"])
+	assert.Equal(t, build["version"], actualResponse["data"].(map[string]interface{})["version"])
+	assert.NotContains(t, build, "deps")
 }
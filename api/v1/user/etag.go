@@ -0,0 +1,59 @@
+package user
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"gin-app/models"
+	"gin-app/responses"
+)
+
+// etag returns the strong entity tag for the current version of user
+func etag(user *models.User) string {
+	return fmt.Sprintf(`"%d"`, user.Version)
+}
+
+// matchesETag reports whether an If-Match / If-None-Match header value lists
+// tag. If-None-Match uses weak comparison, so W/ prefixes are ignored there
+func matchesETag(header, tag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIfMatch enforces an If-Match precondition against the current version of
+// user. It responds with 412 and returns false when the precondition fails
+func checkIfMatch(c *gin.Context, user *models.User) bool {
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" || matchesETag(ifMatch, etag(user), false) {
+		return true
+	}
+	c.Header("ETag", etag(user))
+	responses.Error(c, http.StatusPreconditionFailed, "user.precondition_failed")
+	return false
+}
+
+// updateFailed reports an error from UserRepository.Update. A version conflict
+// on a request with If-Match is a failed precondition (412); everything else,
+// including unconditional conflicts (409), is left to ErrorHandlerMiddleware
+func updateFailed(c *gin.Context, err error) {
+	if stderrors.Is(err, models.ErrVersionConflict) && c.GetHeader("If-Match") != "" {
+		responses.Error(c, http.StatusPreconditionFailed, "user.precondition_failed")
+		return
+	}
+	c.Error(err)
+}
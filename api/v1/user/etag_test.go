@@ -0,0 +1,90 @@
+package user
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+
+	"gin-app/handler"
+	"gin-app/models"
+)
+
+func setupETagRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	models.SetPasswordHasher(models.NewBcryptHasher(bcrypt.MinCost))
+
+	repo := models.NewInMemoryUserRepository()
+	assert.Nil(t, repo.Create(&models.User{ID: "1", Username: "alice", Email: "alice@example.com"}))
+
+	h := NewUserHandler(repo)
+	router := gin.New()
+	router.Use(handler.ErrorHandlerMiddleware(testErrorMapper()))
+	router.GET("/users/:id", h.GetUser)
+	router.PUT("/users/:id", h.UpdateUser)
+	router.PATCH("/users/:id", h.PatchUser)
+	router.DELETE("/users/:id", h.DeleteUser)
+	return router
+}
+
+func conditional(router *gin.Engine, method, path, header, tag, contentType, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if tag != "" {
+		req.Header.Set(header, tag)
+	}
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestGetUserConditional(t *testing.T) {
+	router := setupETagRouter(t)
+
+	resp := conditional(router, "GET", "/users/1", "", "", "", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, `"1"`, resp.Header().Get("ETag"))
+
+	resp = conditional(router, "GET", "/users/1", "If-None-Match", `W/"1"`, "", "")
+	assert.Equal(t, http.StatusNotModified, resp.Code)
+	assert.Empty(t, resp.Body.String())
+
+	resp = conditional(router, "GET", "/users/1", "If-None-Match", `"0"`, "", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestUpdateUserIfMatch(t *testing.T) {
+	router := setupETagRouter(t)
+
+	resp := conditional(router, "PUT", "/users/1", "If-Match", `"1"`, "application/json", `{"username":"alicia"}`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, `"2"`, resp.Header().Get("ETag"))
+
+	// A second writer still holding version 1 loses
+	resp = conditional(router, "PUT", "/users/1", "If-Match", `"1"`, "application/json", `{"username":"other"}`)
+	assert.Equal(t, http.StatusPreconditionFailed, resp.Code)
+	assert.Equal(t, `"2"`, resp.Header().Get("ETag"))
+
+	resp = conditional(router, "PATCH", "/users/1", "If-Match", `"1"`, MergePatchContentType, `{"username":"other"}`)
+	assert.Equal(t, http.StatusPreconditionFailed, resp.Code)
+
+	resp = conditional(router, "PATCH", "/users/1", "If-Match", `"2"`, MergePatchContentType, `{"username":"ally"}`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, `"3"`, resp.Header().Get("ETag"))
+
+	// Unconditional requests keep working
+	resp = conditional(router, "PUT", "/users/1", "", "", "application/json", `{"username":"alice"}`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, `"4"`, resp.Header().Get("ETag"))
+
+	resp = conditional(router, "DELETE", "/users/1", "If-Match", `"3"`, "", "")
+	assert.Equal(t, http.StatusPreconditionFailed, resp.Code)
+	resp = conditional(router, "DELETE", "/users/1", "If-Match", `"4"`, "", "")
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+}
@@ -1,14 +1,32 @@
 package user
 
 import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
 
 	"gin-app/errors"
+	"gin-app/handler"
+	"gin-app/log"
+	"gin-app/metrics"
 	"gin-app/models"
 	"gin-app/responses"
+	"gin-app/security"
+)
+
+// Access policies for user routes, shared by the v1 and legacy route registrations
+var (
+	ListUsersPolicy   = security.Require(models.PermissionUsersRead)
+	ReadUserPolicy    = security.SelfOr("id", models.PermissionUsersRead)
+	UpdateUserPolicy  = security.SelfOr("id", models.PermissionUsersWrite)
+	DeleteUserPolicy  = security.SelfOr("id", models.PermissionUsersAdmin)
+	ManageRolesPolicy = security.Require(models.PermissionUsersAdmin)
 )
 
 // UserHandler handles user-related HTTP requests
@@ -23,6 +41,11 @@ func NewUserHandler(userRepo models.UserRepository) *UserHandler {
 	}
 }
 
+// repo returns the repository with its calls traced under the request's span
+func (h *UserHandler) repo(c *gin.Context) models.UserRepository {
+	return models.TracedUserRepository(c.Request.Context(), h.userRepo)
+}
+
 // CreateUserRequest represents the request body for creating a user
 type CreateUserRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=50"`
@@ -37,15 +60,23 @@ type UpdateUserRequest struct {
 	Password string `json:"password" binding:"omitempty,min=6"`
 }
 
-// RegisterRoutes registers all user-related routes
-func (h *UserHandler) RegisterRoutes(router *gin.RouterGroup) {
+// UpdateUserRolesRequest represents the request body for replacing a user's roles
+type UpdateUserRolesRequest struct {
+	Roles []string `json:"roles" binding:"required,min=1,dive,required"`
+}
+
+// RegisterRoutes registers all user-related routes. Registration is public;
+// every other route requires authMiddleware and the route's access policy
+func (h *UserHandler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
 	users := router.Group("/users")
 	{
-		users.POST("", h.CreateUser)
-		users.GET("", h.GetAllUsers)
-		users.GET("/:id", h.GetUser)
-		users.PUT("/:id", h.UpdateUser)
-		users.DELETE("/:id", h.DeleteUser)
+		users.POST("", handler.Traced("UserHandler.CreateUser", h.CreateUser))
+		users.GET("", authMiddleware, handler.Authorize(ListUsersPolicy), handler.Traced("UserHandler.GetAllUsers", h.GetAllUsers))
+		users.GET("/:id", authMiddleware, handler.Authorize(ReadUserPolicy), handler.Traced("UserHandler.GetUser", h.GetUser))
+		users.PUT("/:id", authMiddleware, handler.Authorize(UpdateUserPolicy), handler.Traced("UserHandler.UpdateUser", h.UpdateUser))
+		users.PATCH("/:id", authMiddleware, handler.Authorize(UpdateUserPolicy), handler.Traced("UserHandler.PatchUser", h.PatchUser))
+		users.DELETE("/:id", authMiddleware, handler.Authorize(DeleteUserPolicy), handler.Traced("UserHandler.DeleteUser", h.DeleteUser))
+		users.PUT("/:id/roles", authMiddleware, handler.Authorize(ManageRolesPolicy), handler.Traced("UserHandler.UpdateUserRoles", h.UpdateUserRoles))
 	}
 }
 
@@ -64,21 +95,7 @@ func (h *UserHandler) RegisterRoutes(router *gin.RouterGroup) {
 func (h *UserHandler) CreateUser(c *gin.Context) {
 	var req CreateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		responses.BadRequest(c, "Invalid request body: "+err.Error())
-		return
-	}
-
-	// Check if username already exists
-	_, err := h.userRepo.GetByUsername(req.Username)
-	if err == nil {
-		responses.Conflict(c, "Username already taken")
-		return
-	}
-
-	// Check if email already exists
-	_, err = h.userRepo.GetByEmail(req.Email)
-	if err == nil {
-		responses.Conflict(c, "Email already in use")
+		c.Error(errors.BindingError(err))
 		return
 	}
 
@@ -87,17 +104,27 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		ID:        uuid.New().String(),
 		Username:  req.Username,
 		Email:     req.Email,
-		Password:  req.Password, // In a real application, you would hash this
+		Roles:     []string{models.RoleUser},
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
+	if err := user.SetPassword(req.Password); err != nil {
+		log.FromContext(c).WithError(err).Error("failed to hash password")
+		c.Error(errors.NewLocalizedError(http.StatusInternalServerError, "error.hash_password"))
+		return
+	}
 
-	if err := h.userRepo.Create(user); err != nil {
-		responses.InternalServerError(c, "Failed to create user: "+err.Error())
+	// The repository rejects duplicate usernames and emails
+	if err := h.repo(c).Create(user); err != nil {
+		c.Error(err)
 		return
 	}
+	log.AddFields(c, logrus.Fields{"target_id": user.ID})
+	log.FromContext(c).WithField("username", user.Username).Info("user created")
+	metrics.UsersCreated.Inc()
 
-	responses.Created(c, "User created successfully", user)
+	c.Header("ETag", etag(user))
+	responses.Created(c, "user.created", user)
 }
 
 // GetUser retrieves a user by ID
@@ -106,38 +133,138 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 // @Tags users
 // @Produce json
 // @Param id path string true "User ID"
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {object} responses.Response
+// @Success 304 "Not Modified"
+// @Failure 401 {object} responses.Response
+// @Failure 403 {object} responses.Response
 // @Failure 404 {object} responses.Response
 // @Failure 500 {object} responses.Response
 // @Router /api/v1/users/{id} [get]
 func (h *UserHandler) GetUser(c *gin.Context) {
 	id := c.Param("id")
-	user, err := h.userRepo.GetByID(id)
+	user, err := h.repo(c).GetByID(id)
 	if err != nil {
-		appErr := errors.NotFound("User not found", map[string]string{"id": id})
-		responses.Error(c, appErr.StatusCode, appErr.Message, appErr.Details)
+		c.Error(err)
+		return
+	}
+
+	// Conditional GET: the client already has the current version
+	c.Header("ETag", etag(user))
+	if ifNoneMatch := c.GetHeader("If-None-Match"); ifNoneMatch != "" && matchesETag(ifNoneMatch, etag(user), true) {
+		c.Status(http.StatusNotModified)
 		return
 	}
 
-	responses.Success(c, "User retrieved successfully", user)
+	responses.Success(c, "user.retrieved", user)
+}
+
+// ListUsersQuery represents the query parameters for listing users
+type ListUsersQuery struct {
+	Limit         int       `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset        int       `form:"offset" binding:"omitempty,min=0"`
+	Cursor        string    `form:"cursor"`
+	Sort          string    `form:"sort" binding:"omitempty,oneof=created_at -created_at username -username"`
+	EmailDomain   string    `form:"email_domain" binding:"omitempty,max=255"`
+	CreatedAfter  time.Time `form:"created_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedBefore time.Time `form:"created_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	Q             string    `form:"q" binding:"omitempty,max=100"`
 }
 
-// GetAllUsers retrieves all users
-// @Summary Get all users
-// @Description Get a list of all users
+// GetAllUsers retrieves a page of users
+// @Summary List users
+// @Description Get a filtered, sorted page of users. Use either offset or the opaque cursor from a previous page
 // @Tags users
 // @Produce json
+// @Param limit query int false "Page size (1-100, default 20)"
+// @Param offset query int false "Number of users to skip"
+// @Param cursor query string false "Opaque cursor from next_cursor or prev_cursor"
+// @Param sort query string false "created_at, -created_at, username or -username"
+// @Param email_domain query string false "Only users whose email is in this domain"
+// @Param created_after query string false "RFC3339 inclusive lower bound on created_at"
+// @Param created_before query string false "RFC3339 exclusive upper bound on created_at"
+// @Param q query string false "Case-insensitive substring of username or email"
 // @Success 200 {object} responses.Response
+// @Failure 400 {object} responses.Response
+// @Failure 401 {object} responses.Response
+// @Failure 403 {object} responses.Response
 // @Failure 500 {object} responses.Response
 // @Router /api/v1/users [get]
 func (h *UserHandler) GetAllUsers(c *gin.Context) {
-	users, err := h.userRepo.GetAll()
+	var req ListUsersQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(errors.BindingError(err))
+		return
+	}
+
+	query := models.UserQuery{
+		Limit:         req.Limit,
+		Offset:        req.Offset,
+		Cursor:        req.Cursor,
+		SortBy:        strings.TrimPrefix(req.Sort, "-"),
+		SortDesc:      strings.HasPrefix(req.Sort, "-"),
+		EmailDomain:   req.EmailDomain,
+		CreatedAfter:  req.CreatedAfter,
+		CreatedBefore: req.CreatedBefore,
+		Search:        req.Q,
+	}
+	if query.Limit == 0 {
+		query.Limit = models.DefaultUserQueryLimit
+	}
+
+	page, err := h.repo(c).List(query)
 	if err != nil {
-		responses.InternalServerError(c, "Failed to retrieve users: "+err.Error())
+		c.Error(err)
 		return
 	}
 
-	responses.Success(c, "Users retrieved successfully", users)
+	responses.Paginated(c, "user.listed", page.Users, paginationFor(c, query, page))
+}
+
+// paginationFor builds pagination metadata and navigation links. Links keep the
+// request's filters and sort, and use cursors when the request did
+func paginationFor(c *gin.Context, query models.UserQuery, page *models.UserPage) *responses.Pagination {
+	link := func(set map[string]string) string {
+		u := *c.Request.URL
+		values := u.Query()
+		for key, value := range set {
+			if value == "" {
+				values.Del(key)
+			} else {
+				values.Set(key, value)
+			}
+		}
+		u.RawQuery = values.Encode()
+		u.Scheme, u.Host = "", ""
+		return u.String()
+	}
+
+	pagination := &responses.Pagination{
+		Total:      page.Total,
+		Limit:      query.Limit,
+		Offset:     page.Offset,
+		NextCursor: page.NextCursor,
+		PrevCursor: page.PrevCursor,
+		Links:      responses.Links{Self: link(nil)},
+	}
+
+	if query.Cursor != "" {
+		if page.NextCursor != "" {
+			pagination.Links.Next = link(map[string]string{"cursor": page.NextCursor, "offset": ""})
+		}
+		if page.PrevCursor != "" {
+			pagination.Links.Prev = link(map[string]string{"cursor": page.PrevCursor, "offset": ""})
+		}
+		return pagination
+	}
+
+	if next := page.Offset + len(page.Users); next < page.Total {
+		pagination.Links.Next = link(map[string]string{"offset": strconv.Itoa(next)})
+	}
+	if page.Offset > 0 {
+		pagination.Links.Prev = link(map[string]string{"offset": strconv.Itoa(max(0, page.Offset-query.Limit))})
+	}
+	return pagination
 }
 
 // UpdateUser updates an existing user
@@ -148,69 +275,62 @@ func (h *UserHandler) GetAllUsers(c *gin.Context) {
 // @Produce json
 // @Param id path string true "User ID"
 // @Param user body UpdateUserRequest true "User information"
+// @Param If-Match header string false "ETag the update is conditional on"
 // @Success 200 {object} responses.Response
 // @Failure 400 {object} responses.Response
+// @Failure 401 {object} responses.Response
+// @Failure 403 {object} responses.Response
 // @Failure 404 {object} responses.Response
 // @Failure 409 {object} responses.Response
+// @Failure 412 {object} responses.Response
 // @Failure 500 {object} responses.Response
 // @Router /api/v1/users/{id} [put]
 func (h *UserHandler) UpdateUser(c *gin.Context) {
 	id := c.Param("id")
 
 	// Check if user exists
-	user, err := h.userRepo.GetByID(id)
+	user, err := h.repo(c).GetByID(id)
 	if err != nil {
-		appErr := errors.NotFound("User not found", map[string]string{"id": id})
-		responses.Error(c, appErr.StatusCode, appErr.Message, appErr.Details)
+		c.Error(err)
+		return
+	}
+	if !checkIfMatch(c, user) {
 		return
 	}
 
 	// Bind request
 	var req UpdateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		responses.BadRequest(c, "Invalid request body: "+err.Error())
+		c.Error(errors.BindingError(err))
 		return
 	}
 
 	// Update user fields if provided
 	if req.Username != "" {
-		// Check if username is already taken by another user
-		if user.Username != req.Username {
-			existingUser, err := h.userRepo.GetByUsername(req.Username)
-			if err == nil && existingUser.ID != id {
-				responses.Conflict(c, "Username already taken")
-				return
-			}
-		}
 		user.Username = req.Username
 	}
-
 	if req.Email != "" {
-		// Check if email is already in use by another user
-		if user.Email != req.Email {
-			existingUser, err := h.userRepo.GetByEmail(req.Email)
-			if err == nil && existingUser.ID != id {
-				responses.Conflict(c, "Email already in use")
-				return
-			}
-		}
 		user.Email = req.Email
 	}
 
 	if req.Password != "" {
-		// In a real application, you would hash this password
-		user.Password = req.Password
+		if err := user.SetPassword(req.Password); err != nil {
+			log.FromContext(c).WithError(err).Error("failed to hash password")
+			c.Error(errors.NewLocalizedError(http.StatusInternalServerError, "error.hash_password"))
+			return
+		}
 	}
 
 	user.UpdatedAt = time.Now()
 
-	// Save updated user
-	if err := h.userRepo.Update(user); err != nil {
-		responses.InternalServerError(c, "Failed to update user: "+err.Error())
+	// Save updated user; the repository rejects duplicate usernames and emails
+	if err := h.repo(c).Update(user); err != nil {
+		updateFailed(c, err)
 		return
 	}
 
-	responses.Success(c, "User updated successfully", user)
+	c.Header("ETag", etag(user))
+	responses.Success(c, "user.updated", user)
 }
 
 // DeleteUser deletes a user by ID
@@ -218,26 +338,95 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 // @Description Delete a user by their ID
 // @Tags users
 // @Param id path string true "User ID"
+// @Param If-Match header string false "ETag the deletion is conditional on"
 // @Success 204 "No Content"
+// @Failure 401 {object} responses.Response
+// @Failure 403 {object} responses.Response
 // @Failure 404 {object} responses.Response
+// @Failure 412 {object} responses.Response
 // @Failure 500 {object} responses.Response
 // @Router /api/v1/users/{id} [delete]
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	id := c.Param("id")
 
 	// Check if user exists
-	_, err := h.userRepo.GetByID(id)
+	user, err := h.repo(c).GetByID(id)
 	if err != nil {
-		appErr := errors.NotFound("User not found", map[string]string{"id": id})
-		responses.Error(c, appErr.StatusCode, appErr.Message, appErr.Details)
+		c.Error(err)
+		return
+	}
+	if !checkIfMatch(c, user) {
 		return
 	}
 
 	// Delete user
-	if err := h.userRepo.Delete(id); err != nil {
-		responses.InternalServerError(c, "Failed to delete user: "+err.Error())
+	if err := h.repo(c).Delete(id); err != nil {
+		c.Error(err)
 		return
 	}
+	log.FromContext(c).WithField("target_id", id).Info("user deleted")
+	metrics.UsersDeleted.Inc()
 
 	responses.NoContent(c)
 }
+
+// UpdateUserRoles replaces a user's roles
+// @Summary Update a user's roles
+// @Description Replace the roles assigned to a user (requires users:admin)
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param roles body UpdateUserRolesRequest true "Roles"
+// @Param If-Match header string false "ETag the update is conditional on"
+// @Success 200 {object} responses.Response
+// @Failure 400 {object} responses.Response
+// @Failure 401 {object} responses.Response
+// @Failure 403 {object} responses.Response
+// @Failure 404 {object} responses.Response
+// @Failure 409 {object} responses.Response
+// @Failure 412 {object} responses.Response
+// @Failure 500 {object} responses.Response
+// @Router /api/v1/users/{id}/roles [put]
+func (h *UserHandler) UpdateUserRoles(c *gin.Context) {
+	id := c.Param("id")
+
+	// Check if user exists
+	user, err := h.repo(c).GetByID(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if !checkIfMatch(c, user) {
+		return
+	}
+
+	// Bind request
+	var req UpdateUserRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.BindingError(err))
+		return
+	}
+
+	for i, role := range req.Roles {
+		if !models.IsValidRole(role) {
+			c.Error(errors.FieldValidationError(fmt.Sprintf("roles[%d]", i), "oneof", "validation.unknown_role", role))
+			return
+		}
+	}
+
+	previous := user.Roles
+	user.Roles = req.Roles
+	if err := h.repo(c).Update(user); err != nil {
+		updateFailed(c, err)
+		return
+	}
+	log.FromContext(c).WithFields(logrus.Fields{
+		"target_id": id,
+		"from":      previous,
+		"to":        user.Roles,
+	}).Info("user roles changed")
+
+	c.Header("ETag", etag(user))
+	responses.Success(c, "user.roles_updated", user)
+}
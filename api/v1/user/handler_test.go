@@ -0,0 +1,121 @@
+package user
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+
+	"gin-app/config"
+	apperrors "gin-app/errors"
+	"gin-app/handler"
+	"gin-app/log"
+	"gin-app/models"
+)
+
+// testErrorMapper maps repository errors to responses as the application does
+func testErrorMapper() *apperrors.Mapper {
+	mapper := apperrors.NewMapper()
+	models.RegisterErrorMappings(mapper)
+	return mapper
+}
+
+// TestPasswordsNeverLogged sends passwords through every path that logs and
+// checks that none of them reaches the log files
+func TestPasswordsNeverLogged(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	models.SetPasswordHasher(models.NewBcryptHasher(bcrypt.MinCost))
+
+	dir := t.TempDir()
+	cfg := config.Default().Log
+	cfg.Level = "trace"
+	cfg.Output = []config.SinkConfig{
+		{Type: "file", Filename: filepath.Join(dir, "app.json"), Format: "json"},
+		{Type: "file", Filename: filepath.Join(dir, "app.txt"), Format: "text"},
+	}
+	cfg.Access.Filename = filepath.Join(dir, "access.log")
+	assert.Nil(t, log.Configure(cfg))
+	defer log.Configure(config.Default().Log)
+
+	h := NewUserHandler(models.NewInMemoryUserRepository())
+	router := gin.New()
+	router.Use(handler.RequestIDMiddleware(), handler.LoggerMiddleware(), handler.ErrorHandlerMiddleware(testErrorMapper()), handler.RecoveryMiddleware())
+	router.POST("/users", h.CreateUser)
+	// A careless handler that logs the whole request and panics with the password
+	router.POST("/careless", func(c *gin.Context) {
+		var req CreateUserRequest
+		_ = c.ShouldBindJSON(&req)
+		log.FromContext(c).WithField("request", req).Debug("signup request")
+		log.FromContext(c).Infof("creating %s with password=%s", req.Username, req.Password)
+		panic(fmt.Errorf("cannot store password: %s", req.Password))
+	})
+
+	const password = "hunter2-correct-horse"
+	body := `{"username":"alice","email":"alice@example.com","password":"` + password + `"}`
+	for _, path := range []string{"/users", "/careless", "/users?password=" + password} {
+		req, _ := http.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	for _, name := range []string{"app.json", "app.txt", "access.log"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		assert.Nil(t, err)
+		written := string(data)
+		assert.Contains(t, written, "[REDACTED]", name)
+		assert.NotContains(t, written, password, name)
+		assert.NotContains(t, written, "alice@example.com", name)
+	}
+}
+
+// TestUpdateUserRolesRejectsUnknownRole checks that an unknown role is reported
+// on its field and translated once, in the caller's language
+func TestUpdateUserRolesRejectsUnknownRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := models.NewInMemoryUserRepository()
+	assert.Nil(t, repo.Create(&models.User{ID: "1", Username: "alice", Email: "alice@example.com"}))
+
+	router := gin.New()
+	router.Use(handler.LocaleMiddleware(), handler.ErrorHandlerMiddleware(testErrorMapper()))
+	router.PUT("/users/:id/roles", NewUserHandler(repo).UpdateUserRoles)
+
+	req, _ := http.NewRequest("PUT", "/users/1/roles", strings.NewReader(`{"roles":["user","root"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "zh-CN")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"roles[1]":{"code":"oneof","message":"未知角色：root"}`)
+}
+
+// TestCreateUserHidesInternalErrors checks that a failure to hash the password
+// goes through the error handler without exposing the underlying error
+func TestCreateUserHidesInternalErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	models.SetPasswordHasher(failingHasher{})
+	defer models.SetPasswordHasher(models.NewBcryptHasher(bcrypt.MinCost))
+
+	router := gin.New()
+	router.Use(handler.LocaleMiddleware(), handler.ErrorHandlerMiddleware(testErrorMapper()))
+	router.POST("/users", NewUserHandler(models.NewInMemoryUserRepository()).CreateUser)
+
+	req, _ := http.NewRequest("POST", "/users", strings.NewReader(`{"username":"alice","email":"alice@example.com","password":"correct-horse-battery"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/problem+json")
+	req.Header.Set("Accept-Language", "zh-CN")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Contains(t, resp.Header().Get("Content-Type"), "application/problem+json")
+	assert.Contains(t, resp.Body.String(), "密码哈希失败")
+	assert.NotContains(t, resp.Body.String(), "hasher internals")
+}
@@ -0,0 +1,166 @@
+package user
+
+import (
+	"bytes"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"net/http"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+
+	"gin-app/errors"
+	"gin-app/log"
+	"gin-app/responses"
+)
+
+// Media types accepted by PatchUser
+const (
+	MergePatchContentType = "application/merge-patch+json" // RFC 7396
+	JSONPatchContentType  = "application/json-patch+json"  // RFC 6902
+)
+
+// acceptPatch is advertised in the Accept-Patch header (RFC 5789)
+const acceptPatch = MergePatchContentType + ", " + JSONPatchContentType
+
+// userDocument is the JSON view of a user that PATCH requests operate on.
+// Password is write-only: it is absent from the document and may be added
+type userDocument struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password,omitempty"`
+}
+
+// PatchUser partially updates a user
+// @Summary Patch a user
+// @Description Partially update a user with a JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902) document
+// @Tags users
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param If-Match header string false "ETag the update is conditional on"
+// @Success 200 {object} responses.Response
+// @Failure 400 {object} responses.Response
+// @Failure 401 {object} responses.Response
+// @Failure 403 {object} responses.Response
+// @Failure 404 {object} responses.Response
+// @Failure 409 {object} responses.Response
+// @Failure 412 {object} responses.Response
+// @Failure 415 {object} responses.Response
+// @Failure 422 {object} responses.Response
+// @Failure 500 {object} responses.Response
+// @Router /api/v1/users/{id} [patch]
+func (h *UserHandler) PatchUser(c *gin.Context) {
+	c.Header("Accept-Patch", acceptPatch)
+	id := c.Param("id")
+
+	contentType := c.ContentType()
+	if contentType != MergePatchContentType && contentType != JSONPatchContentType {
+		c.Error(errors.NewLocalizedError(http.StatusUnsupportedMediaType, "patch.unsupported_format", acceptPatch))
+		return
+	}
+
+	// Check if user exists
+	user, err := h.repo(c).GetByID(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if !checkIfMatch(c, user) {
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.Error(errors.NewLocalizedError(http.StatusBadRequest, "patch.read_failed", err))
+		return
+	}
+
+	original, err := json.Marshal(userDocument{Username: user.Username, Email: user.Email})
+	if err != nil {
+		log.FromContext(c).WithError(err).Error("failed to encode user")
+		c.Error(errors.NewLocalizedError(http.StatusInternalServerError, "user.encode_failed"))
+		return
+	}
+
+	// Apply the patch to the user's JSON document
+	var patched []byte
+	if contentType == MergePatchContentType {
+		if !json.Valid(body) {
+			c.Error(errors.NewLocalizedError(http.StatusBadRequest, "patch.invalid_merge_patch", "malformed JSON"))
+			return
+		}
+		patched, err = jsonpatch.MergePatch(original, body)
+		if err != nil {
+			c.Error(errors.NewLocalizedError(http.StatusBadRequest, "patch.invalid_merge_patch", err))
+			return
+		}
+	} else {
+		patch, err := jsonpatch.DecodePatch(body)
+		if err != nil {
+			c.Error(errors.NewLocalizedError(http.StatusBadRequest, "patch.invalid_json_patch", err))
+			return
+		}
+		patched, err = patch.Apply(original)
+		if stderrors.Is(err, jsonpatch.ErrTestFailed) {
+			c.Error(errors.NewLocalizedError(http.StatusConflict, "patch.test_failed", err))
+			return
+		}
+		if err != nil {
+			c.Error(errors.NewLocalizedError(http.StatusUnprocessableEntity, "patch.cannot_apply", err))
+			return
+		}
+	}
+
+	// Decode the result strictly so patches cannot introduce unknown fields
+	var doc userDocument
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&doc); err != nil {
+		c.Error(errors.NewLocalizedError(http.StatusUnprocessableEntity, "patch.invalid_result", err))
+		return
+	}
+
+	// Validate with the same rules as PUT; username and email may change but not be removed
+	req := UpdateUserRequest(doc)
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		appErr := errors.NewLocalizedError(http.StatusUnprocessableEntity, "patch.invalid_user")
+		appErr.Fields = errors.BindingError(err).Fields
+		c.Error(appErr)
+		return
+	}
+	if req.Username == "" {
+		appErr := errors.FieldValidationError("username", "required", "validation.cannot_remove", "username")
+		appErr.StatusCode = http.StatusUnprocessableEntity
+		c.Error(appErr)
+		return
+	}
+	if req.Email == "" {
+		appErr := errors.FieldValidationError("email", "required", "validation.cannot_remove", "email")
+		appErr.StatusCode = http.StatusUnprocessableEntity
+		c.Error(appErr)
+		return
+	}
+
+	user.Username = req.Username
+	user.Email = req.Email
+	if req.Password != "" {
+		if err := user.SetPassword(req.Password); err != nil {
+			log.FromContext(c).WithError(err).Error("failed to hash password")
+			c.Error(errors.NewLocalizedError(http.StatusInternalServerError, "error.hash_password"))
+			return
+		}
+	}
+
+	// Save updated user; the repository rejects duplicate usernames and emails
+	if err := h.repo(c).Update(user); err != nil {
+		updateFailed(c, err)
+		return
+	}
+
+	c.Header("ETag", etag(user))
+	responses.Success(c, "user.updated", user)
+}
@@ -0,0 +1,139 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+
+	"gin-app/handler"
+	"gin-app/models"
+)
+
+func setupPatchRouter(t *testing.T) (*gin.Engine, models.UserRepository) {
+	gin.SetMode(gin.TestMode)
+	models.SetPasswordHasher(models.NewBcryptHasher(bcrypt.MinCost))
+
+	repo := models.NewInMemoryUserRepository()
+	assert.Nil(t, repo.Create(&models.User{ID: "1", Username: "alice", Email: "alice@example.com"}))
+	assert.Nil(t, repo.Create(&models.User{ID: "2", Username: "bob", Email: "bob@example.com"}))
+
+	router := gin.New()
+	router.Use(handler.LocaleMiddleware(), handler.ErrorHandlerMiddleware(testErrorMapper()))
+	router.PATCH("/users/:id", NewUserHandler(repo).PatchUser)
+	return router, repo
+}
+
+func patch(router *gin.Engine, path, contentType, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("PATCH", path, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestPatchUserMergePatch(t *testing.T) {
+	router, repo := setupPatchRouter(t)
+
+	resp := patch(router, "/users/1", MergePatchContentType, `{"email":"alice@example.org","password":"newsecret"}`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	user, _ := repo.GetByID("1")
+	assert.Equal(t, "alice", user.Username)
+	assert.Equal(t, "alice@example.org", user.Email)
+	assert.True(t, user.VerifyPassword("newsecret"))
+
+	// Removing a required field is rejected
+	resp = patch(router, "/users/1", MergePatchContentType, `{"username":null}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+	// Same validation rules as PUT
+	resp = patch(router, "/users/1", MergePatchContentType, `{"email":"not-an-email"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+	// Unknown fields cannot be smuggled in
+	resp = patch(router, "/users/1", MergePatchContentType, `{"roles":["admin"]}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+	resp = patch(router, "/users/1", MergePatchContentType, `{"username":"bob"}`)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
+
+func TestPatchUserJSONPatch(t *testing.T) {
+	router, repo := setupPatchRouter(t)
+
+	resp := patch(router, "/users/1", JSONPatchContentType,
+		`[{"op":"test","path":"/username","value":"alice"},{"op":"replace","path":"/username","value":"alicia"}]`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	user, _ := repo.GetByID("1")
+	assert.Equal(t, "alicia", user.Username)
+
+	// Failed test operations leave the user untouched
+	resp = patch(router, "/users/1", JSONPatchContentType,
+		`[{"op":"test","path":"/username","value":"alice"},{"op":"replace","path":"/username","value":"other"}]`)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	user, _ = repo.GetByID("1")
+	assert.Equal(t, "alicia", user.Username)
+
+	resp = patch(router, "/users/1", JSONPatchContentType, `[{"op":"replace","path":"/missing","value":"x"}]`)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+	resp = patch(router, "/users/1", JSONPatchContentType, `{"op":"replace"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestPatchUserContentNegotiation(t *testing.T) {
+	router, _ := setupPatchRouter(t)
+
+	resp := patch(router, "/users/1", "application/json", `{"username":"alicia"}`)
+	assert.Equal(t, http.StatusUnsupportedMediaType, resp.Code)
+	assert.Contains(t, resp.Header().Get("Accept-Patch"), MergePatchContentType)
+
+	resp = patch(router, "/users/999", MergePatchContentType, `{}`)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestPatchUserLocalizesErrors(t *testing.T) {
+	router, _ := setupPatchRouter(t)
+
+	send := func(contentType, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("PATCH", "/users/1", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Accept-Language", "zh-CN")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := send("application/json", `{"username":"alicia"}`)
+	assert.Equal(t, http.StatusUnsupportedMediaType, resp.Code)
+	assert.Contains(t, resp.Body.String(), "不支持的补丁格式")
+
+	// Field messages are translated as well as the top-level message
+	resp = send(MergePatchContentType, `{"username":null}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "参数验证失败")
+	assert.Contains(t, resp.Body.String(), "username 不能被删除")
+}
+
+// failingHasher fails every hash with an error that must not reach clients
+type failingHasher struct{ models.PasswordHasher }
+
+func (failingHasher) Hash(string) (string, error) {
+	return "", errors.New("hasher internals")
+}
+
+func TestPatchUserHidesInternalErrors(t *testing.T) {
+	router, _ := setupPatchRouter(t)
+	models.SetPasswordHasher(failingHasher{})
+
+	resp := patch(router, "/users/1", MergePatchContentType, `{"password":"new-password-123"}`)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Contains(t, resp.Body.String(), "Failed to hash password")
+	assert.NotContains(t, resp.Body.String(), "hasher internals")
+}
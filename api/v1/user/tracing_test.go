@@ -0,0 +1,93 @@
+package user
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"golang.org/x/crypto/bcrypt"
+
+	"gin-app/handler"
+	"gin-app/log"
+	"gin-app/models"
+	"gin-app/security"
+)
+
+func TestCreateUserTracing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	models.SetPasswordHasher(models.NewBcryptHasher(bcrypt.MinCost))
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	hook := test.NewLocal(log.Logger)
+
+	router := gin.New()
+	router.Use(otelgin.Middleware("gin-app"), handler.LoggerMiddleware(), handler.ErrorHandlerMiddleware(testErrorMapper()))
+	NewUserHandler(models.NewInMemoryUserRepository()).RegisterRoutes(router.Group(""), func(c *gin.Context) { c.Next() })
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req, _ := http.NewRequest("POST", "/users", strings.NewReader(`{"username":"alice","email":"alice@example.com","password":"secret123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		assert.Equal(t, traceID, span.SpanContext().TraceID().String(), span.Name())
+		spans[span.Name()] = span
+	}
+	server, handlerSpan, repoSpan := spans["/users"], spans["UserHandler.CreateUser"], spans["UserRepository.Create"]
+	if assert.NotNil(t, server) && assert.NotNil(t, handlerSpan) && assert.NotNil(t, repoSpan) {
+		// The incoming traceparent is the parent of the request span
+		assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+		assert.Equal(t, server.SpanContext().SpanID(), handlerSpan.Parent().SpanID())
+		assert.Equal(t, handlerSpan.SpanContext().SpanID(), repoSpan.Parent().SpanID())
+	}
+
+	// Request-scoped log lines carry the trace ID
+	entries := hook.AllEntries()
+	if assert.NotEmpty(t, entries) {
+		for _, entry := range entries {
+			assert.Equal(t, traceID, entry.Data["trace_id"], entry.Message)
+		}
+	}
+}
+
+func TestMissingUserIsNotATraceError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	router := gin.New()
+	router.Use(otelgin.Middleware("gin-app"), handler.ErrorHandlerMiddleware(testErrorMapper()))
+	// The caller may read its own user, which has been deleted
+	authenticate := func(c *gin.Context) { security.SetIdentity(c, &security.Identity{UserID: "missing"}) }
+	NewUserHandler(models.NewInMemoryUserRepository()).RegisterRoutes(router.Group(""), authenticate)
+
+	req, _ := http.NewRequest("GET", "/users/missing", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	names := map[string]bool{}
+	for _, span := range recorder.Ended() {
+		names[span.Name()] = true
+		assert.NotEqual(t, codes.Error, span.Status().Code, span.Name())
+		assert.Empty(t, span.Events(), span.Name())
+	}
+	assert.True(t, names["UserHandler.GetUser"])
+	assert.True(t, names["UserRepository.GetByID"])
+}
@@ -0,0 +1,105 @@
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"sync"
+)
+
+// 链接时通过 -ldflags 注入，例如：
+//
+//	go build -ldflags "-X gin-app/buildinfo.version=v1.2.0 -X gin-app/buildinfo.commit=$(git rev-parse HEAD) -X gin-app/buildinfo.buildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// 未注入的值从 runtime/debug.ReadBuildInfo 记录的模块版本和VCS信息中获取
+var (
+	version   string
+	commit    string
+	dirty     string // "true" 或 "false"
+	buildTime string
+)
+
+// DevVersion 无法确定版本号时（例如 go run）使用的版本
+const DevVersion = "dev"
+
+// Info 当前二进制的构建信息
+type Info struct {
+	Version    string   `json:"version"`
+	Commit     string   `json:"commit,omitempty"`
+	Dirty      bool     `json:"dirty"`                 // 构建时工作区是否有未提交的修改
+	BuildTime  string   `json:"build_time,omitempty"`  // 构建时间，仅在通过 -ldflags 注入时可用
+	CommitTime string   `json:"commit_time,omitempty"` // 提交时间，来自VCS信息
+	GoVersion  string   `json:"go_version"`
+	Module     string   `json:"module,omitempty"`
+	Deps       []Module `json:"deps,omitempty"`
+}
+
+// Module 编译进二进制的依赖模块
+type Module struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	Sum     string `json:"sum,omitempty"`
+	Replace string `json:"replace,omitempty"` // 被replace指令替换时的目标模块
+}
+
+var (
+	once sync.Once
+	info Info
+)
+
+// Get 返回当前二进制的构建信息，结果只计算一次
+func Get() Info {
+	once.Do(func() { info = read() })
+	return info
+}
+
+// Version 返回当前二进制的版本号
+func Version() string {
+	return Get().Version
+}
+
+// read 合并 -ldflags 注入的值和Go工具链记录的构建信息，注入的值优先
+func read() Info {
+	i := Info{GoVersion: runtime.Version()}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		i.Module = bi.Main.Path
+		if bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			i.Version = bi.Main.Version
+		}
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				i.Commit = s.Value
+			case "vcs.modified":
+				i.Dirty = s.Value == "true"
+			case "vcs.time":
+				i.CommitTime = s.Value
+			}
+		}
+		for _, dep := range bi.Deps {
+			m := Module{Path: dep.Path, Version: dep.Version, Sum: dep.Sum}
+			if dep.Replace != nil {
+				m.Replace = dep.Replace.Path + "@" + dep.Replace.Version
+			}
+			i.Deps = append(i.Deps, m)
+		}
+	}
+
+	if version != "" {
+		i.Version = version
+	}
+	if commit != "" {
+		i.Commit = commit
+	}
+	if d, err := strconv.ParseBool(dirty); err == nil {
+		i.Dirty = d
+	}
+	if buildTime != "" {
+		i.BuildTime = buildTime
+	}
+	if i.Version == "" {
+		i.Version = DevVersion
+	}
+	return i
+}
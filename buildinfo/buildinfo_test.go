@@ -0,0 +1,35 @@
+package buildinfo
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadDefaults(t *testing.T) {
+	i := read()
+	assert.Equal(t, DevVersion, i.Version)
+	assert.Equal(t, runtime.Version(), i.GoVersion)
+	assert.Empty(t, i.BuildTime)
+}
+
+func TestReadLinkerValues(t *testing.T) {
+	defer func(v, c, d, b string) { version, commit, dirty, buildTime = v, c, d, b }(version, commit, dirty, buildTime)
+	version, commit, dirty, buildTime = "v1.2.0", "0123abc", "true", "2026-01-02T03:04:05Z"
+
+	i := read()
+	assert.Equal(t, "v1.2.0", i.Version)
+	assert.Equal(t, "0123abc", i.Commit)
+	assert.True(t, i.Dirty)
+	assert.Equal(t, "2026-01-02T03:04:05Z", i.BuildTime)
+
+	// An explicit "false" overrides the VCS dirty flag
+	dirty = "false"
+	assert.False(t, read().Dirty)
+}
+
+func TestGetIsStable(t *testing.T) {
+	assert.Equal(t, Get(), Get())
+	assert.Equal(t, Get().Version, Version())
+}
@@ -2,68 +2,253 @@ package config
 
 import (
 	"fmt"
+	"reflect"
+	"strings"
+	"time"
 
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 )
 
-// AppConfig 应用程序配置
+// AppConfig 应用程序配置，版本号来自buildinfo
 type AppConfig struct {
-	Name    string
-	Version string
-	Port    int
+	Name string
+	Port int
+}
+
+// ServerConfig HTTP服务配置，除TrustedProxies外支持热更新
+type ServerConfig struct {
+	RequestTimeout time.Duration // 请求超时时间
+	CORSOrigins    []string      // 允许跨域的来源，"*" 表示全部
+	TrustedProxies []string      // 可信反向代理的IP或CIDR，只有来自它们的X-Forwarded-For才被采用；为空时客户端IP为连接的对端地址
 }
 
 // LogConfig 日志配置
 type LogConfig struct {
 	Level      string
 	Format     string
-	Output     string
-	Filename   string
-	MaxSize    int
+	Output     []SinkConfig // 日志输出，可写作 "stdout"、"stdout,file" 或带独立级别和格式的列表
+	Filename   string       // file输出的默认文件名，相对路径位于logs目录下
+	MaxSize    int          // 单个日志文件大小（MB），以下轮转参数对所有日志文件生效
 	MaxBackups int
 	MaxAge     int
 	Compress   bool
+	Access     AccessLogConfig
+	Redact     RedactConfig
 }
 
-// Config 全局配置
-type Config struct {
-	App AppConfig
-	Log LogConfig
+// SinkConfig 单个日志输出，未设置的级别、格式和文件名继承LogConfig
+type SinkConfig struct {
+	Type     string // stdout、file 或 syslog
+	Level    string
+	Format   string
+	Filename string // file输出的文件名
+	Network  string // syslog网络类型，与Address都为空时写入本机syslog/journald套接字
+	Address  string // syslog地址
+	Tag      string // syslog标识，为空时使用程序名
 }
 
-// GlobalConfig 全局配置实例
-var GlobalConfig Config
-
-func init() {
-	// 设置配置文件
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath(".")
+// AccessLogConfig HTTP访问日志配置
+type AccessLogConfig struct {
+	Filename string // 访问日志文件名，为空时访问日志写入应用日志
+	Format   string // 为空时继承log.format
+}
 
-	// 设置默认配置
-	setDefaults()
+// RedactConfig 日志脱敏规则，对所有日志条目的消息和字段生效
+type RedactConfig struct {
+	Fields      []string // 字段名包含其中任一词（不区分大小写）时整个值被替换
+	Patterns    []string // 正则表达式，有捕获组时只替换捕获组，否则替换整个匹配
+	QueryParams []string // 字符串中URL查询参数的值被替换
+	Mask        string   // 替换后的文本
+}
 
-	// 读取配置文件
-	if err := viper.ReadInConfig(); err != nil {
-		fmt.Printf("Error reading config file, %s. Using default configuration.\n", err)
+// Sinks 返回填充了继承值的日志输出
+func (c LogConfig) Sinks() []SinkConfig {
+	sinks := make([]SinkConfig, len(c.Output))
+	for i, sink := range c.Output {
+		if sink.Level == "" {
+			sink.Level = c.Level
+		}
+		if sink.Format == "" {
+			sink.Format = c.Format
+		}
+		if sink.Type == "file" && sink.Filename == "" {
+			sink.Filename = c.Filename
+		}
+		sinks[i] = sink
 	}
+	return sinks
+}
+
+// DatabaseConfig 存储配置
+type DatabaseConfig struct {
+	Driver      string // memory 或 sqlite
+	DSN         string // sqlite数据库文件路径
+	AutoMigrate bool   // 启动时自动执行未应用的迁移
+}
+
+// PasswordConfig 密码哈希配置
+type PasswordConfig struct {
+	Algorithm  string // bcrypt 或 argon2id
+	BcryptCost int
+	Argon2     Argon2Config
+}
+
+// Argon2Config argon2id参数
+type Argon2Config struct {
+	Time       uint32 // 迭代次数
+	Memory     uint32 // 内存（KiB）
+	Threads    uint8
+	KeyLength  uint32
+	SaltLength uint32
+}
+
+// AuthConfig 认证配置
+type AuthConfig struct {
+	JWTSecret       string         // HS256签名密钥，为空时每次启动随机生成
+	Issuer          string         // JWT签发者
+	AccessTokenTTL  time.Duration  // 访问令牌有效期
+	RefreshTokenTTL time.Duration  // 刷新令牌有效期
+	APIKeys         []APIKeyConfig // 已发放的API Key，按api_key限流时只有这些Key单独计数
+}
+
+// APIKeyConfig 一个已发放的API Key，只保存其SHA-256摘要
+type APIKeyConfig struct {
+	Name   string // 客户端名称，作为限流计数的依据
+	SHA256 string // Key的SHA-256摘要（十六进制），例如 printf %s "$KEY" | sha256sum
+}
 
-	// 解析配置到结构体
-	if err := viper.Unmarshal(&GlobalConfig); err != nil {
-		fmt.Printf("Unable to decode into struct, %v. Using default configuration.\n", err)
+// TracingConfig OpenTelemetry链路追踪配置，启动时生效
+type TracingConfig struct {
+	Exporter    string  // none、stdout、file 或 otlp
+	Endpoint    string  // OTLP/HTTP接收端地址（host:port），为空时使用OTEL_EXPORTER_OTLP_ENDPOINT或localhost:4318
+	Insecure    bool    // OTLP使用HTTP而不是HTTPS
+	Filename    string  // file导出的文件名，相对路径位于logs目录下
+	SampleRatio float64 // 新链路的采样比例（0-1），带traceparent的请求沿用上游的采样决定
+}
+
+// HealthConfig 存活和就绪探针配置，启动时生效
+type HealthConfig struct {
+	Timeout  time.Duration // 单个检查的超时时间
+	Interval time.Duration // 检查结果的缓存时间，期间的探针请求直接返回上次结果
+	// ShutdownDelay 优雅关闭时就绪探针失败后继续接收请求的时间，让负载均衡在关闭监听前摘除实例
+	ShutdownDelay time.Duration
+}
+
+// RateLimitConfig 限流配置，修改后无需重启即可生效
+type RateLimitConfig struct {
+	Enabled bool
+	Rules   []RateLimitRule // 按顺序匹配，第一条匹配的规则生效，没有匹配规则的请求不限流
+}
+
+// RateLimitRule 一条按路由的限流规则
+type RateLimitRule struct {
+	Name      string        // 规则名，不同规则的配额相互独立，默认为 "method route"
+	Method    string        // 为空时匹配所有方法
+	Route     string        // 路由模板，例如 /api/v1/users/:id；以 /* 结尾时匹配前缀，为空时匹配所有路由
+	Key       string        // 计数依据：ip、user 或 api_key，匿名请求和未带API Key的请求按ip计数
+	Algorithm string        // token_bucket 或 sliding_window
+	Limit     int           // 每个window允许的请求数，也是令牌桶的容量
+	Window    time.Duration // 时间窗口
+}
+
+// Config 全局配置
+type Config struct {
+	App       AppConfig
+	Server    ServerConfig
+	Log       LogConfig
+	Database  DatabaseConfig
+	Password  PasswordConfig
+	Auth      AuthConfig
+	Tracing   TracingConfig
+	Health    HealthConfig
+	RateLimit RateLimitConfig
+}
+
+// Default 返回只包含默认值的配置，不读取配置文件、环境变量和命令行参数
+func Default() Config {
+	v := viper.New()
+	setDefaults(v)
+	var cfg Config
+	if err := unmarshal(v, &cfg); err != nil {
+		panic(fmt.Sprintf("config: decoding defaults: %v", err))
 	}
+	return cfg
+}
+
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("app.name", "gin-app")
+	v.SetDefault("app.port", 9000)
+	v.SetDefault("server.requestTimeout", "10s")
+	v.SetDefault("server.corsOrigins", []string{"*"})
+	v.SetDefault("server.trustedProxies", []string{})
+	v.SetDefault("log.level", "info")
+	v.SetDefault("log.format", "json")
+	v.SetDefault("log.output", "stdout")
+	v.SetDefault("log.filename", "app.log")
+	v.SetDefault("log.maxSize", 10)
+	v.SetDefault("log.maxBackups", 3)
+	v.SetDefault("log.maxAge", 28)
+	v.SetDefault("log.compress", true)
+	v.SetDefault("log.access.filename", "")
+	v.SetDefault("log.access.format", "")
+	v.SetDefault("log.redact.fields", []string{"password", "token", "secret", "authorization", "cookie", "email"})
+	v.SetDefault("log.redact.patterns", []string{
+		`(?i)bearer\s+([A-Za-z0-9\-._~+/]+=*)`,                                  // Authorization头中的令牌
+		`eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]*\.[A-Za-z0-9_-]*`,                     // JWT
+		`(?i)(?:password|passwd|secret|token)["']?\s*[:=]\s*["']?([^\s"'&,}]+)`, // key=value形式的密码和令牌
+		`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`,                        // 邮箱地址
+	})
+	v.SetDefault("log.redact.queryParams", []string{"token", "access_token", "refresh_token", "password", "api_key"})
+	v.SetDefault("log.redact.mask", "[REDACTED]")
+	v.SetDefault("database.driver", "memory")
+	v.SetDefault("database.dsn", "data/gin-app.db")
+	v.SetDefault("database.autoMigrate", true)
+	v.SetDefault("password.algorithm", "bcrypt")
+	v.SetDefault("password.bcryptCost", 10)
+	v.SetDefault("password.argon2.time", 3)
+	v.SetDefault("password.argon2.memory", 65536)
+	v.SetDefault("password.argon2.threads", 4)
+	v.SetDefault("password.argon2.keyLength", 32)
+	v.SetDefault("password.argon2.saltLength", 16)
+	v.SetDefault("auth.jwtSecret", "")
+	v.SetDefault("auth.issuer", "gin-app")
+	v.SetDefault("auth.accessTokenTTL", "15m")
+	v.SetDefault("auth.refreshTokenTTL", "168h")
+	v.SetDefault("tracing.exporter", "none")
+	v.SetDefault("tracing.endpoint", "")
+	v.SetDefault("tracing.insecure", false)
+	v.SetDefault("tracing.filename", "traces.json")
+	v.SetDefault("tracing.sampleRatio", 1.0)
+	v.SetDefault("health.timeout", "2s")
+	v.SetDefault("health.interval", "5s")
+	v.SetDefault("health.shutdownDelay", "0s")
+	v.SetDefault("rateLimit.enabled", true)
+	v.SetDefault("rateLimit.rules", []map[string]interface{}{
+		{"name": "login", "method": "POST", "route": "/api/v1/auth/login", "key": "ip", "algorithm": "sliding_window", "limit": 10, "window": "1m"},
+		{"name": "signup", "method": "POST", "route": "/api/v1/users", "key": "ip", "algorithm": "sliding_window", "limit": 20, "window": "1h"},
+		{"name": "users", "route": "/api/v1/users/*", "key": "user", "algorithm": "token_bucket", "limit": 120, "window": "1m"},
+	})
 }
 
-func setDefaults() {
-	viper.SetDefault("app.name", "gin-app")
-	viper.SetDefault("app.version", "1.0.0")
-	viper.SetDefault("app.port", 9000)
-	viper.SetDefault("log.level", "info")
-	viper.SetDefault("log.format", "json")
-	viper.SetDefault("log.output", "stdout")
-	viper.SetDefault("log.filename", "app.log")
-	viper.SetDefault("log.maxSize", 10)
-	viper.SetDefault("log.maxBackups", 3)
-	viper.SetDefault("log.maxAge", 28)
-	viper.SetDefault("log.compress", true)
+// unmarshal 解析配置，除viper默认的转换外还支持把 "stdout,file" 写法的log.output解析为输出列表
+func unmarshal(v *viper.Viper, cfg *Config) error {
+	return v.Unmarshal(cfg, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		stringToSinksHookFunc,
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+	)))
+}
+
+func stringToSinksHookFunc(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String || to != reflect.TypeOf([]SinkConfig{}) {
+		return data, nil
+	}
+	var sinks []SinkConfig
+	for _, typ := range strings.Split(data.(string), ",") {
+		if typ = strings.TrimSpace(typ); typ != "" {
+			sinks = append(sinks, SinkConfig{Type: typ})
+		}
+	}
+	return sinks, nil
 }
@@ -0,0 +1,201 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfig(t *testing.T, path, content string) {
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestDefaultsAreValid(t *testing.T) {
+	cfg := Default()
+	assert.Nil(t, cfg.Validate())
+
+	// Rule lists given as defaults are decoded like those from a file
+	assert.Len(t, cfg.RateLimit.Rules, 3)
+	assert.Equal(t, RateLimitRule{Name: "login", Method: "POST", Route: "/api/v1/auth/login", Key: "ip",
+		Algorithm: "sliding_window", Limit: 10, Window: time.Minute}, cfg.RateLimit.Rules[0])
+}
+
+func TestValidateReportsAllProblems(t *testing.T) {
+	cfg := Default()
+	cfg.App.Port = 70000
+	cfg.Server.TrustedProxies = []string{"10.0.0.0/8", "192.168.1.1", "proxy.local"}
+	cfg.Log.Output = []SinkConfig{{Type: "file"}}
+	cfg.Log.Filename = ""
+	cfg.Log.MaxSize = 0
+	cfg.Log.MaxAge = -1
+	cfg.Password.BcryptCost = 32
+	cfg.Password.Argon2.Threads = 0
+	cfg.Auth.APIKeys = []APIKeyConfig{{Name: "ci", SHA256: "not-a-digest"}}
+	cfg.Tracing.Exporter = "jaeger"
+	cfg.Tracing.SampleRatio = 1.5
+	cfg.RateLimit.Rules = []RateLimitRule{{Route: "/x", Key: "session", Algorithm: "token_bucket", Limit: 1}}
+
+	err := cfg.Validate()
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []string{
+		"app.port 70000 is out of range",
+		"server.trustedProxies entry \"proxy.local\" must be an IP address or CIDR",
+		"log.output[0].filename must be set for file output",
+		"log.maxSize must be positive",
+		"log.maxAge must not be negative",
+		"password.bcryptCost 32 must be between 4 and 31",
+		"password.argon2.threads must be positive",
+		"auth.apiKeys[0].sha256 must be a hex-encoded SHA-256 digest",
+		"tracing.exporter \"jaeger\" is unknown",
+		"tracing.sampleRatio 1.5 must be between 0 and 1",
+		"rateLimit.rules[0].key \"session\" is unknown",
+		"rateLimit.rules[0].window must be positive",
+	}, validationErr.Problems)
+	assert.ErrorContains(t, err, "invalid config: app.port 70000 is out of range; server.trustedProxies entry")
+}
+
+func TestLoadWithoutConfigFile(t *testing.T) {
+	// Tests run in the package directory, which has no config.yaml
+	cfg, err := Load(Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, Default(), *cfg)
+
+	// An explicit path must exist
+	_, err = Load(Options{Path: "missing.yaml"})
+	assert.ErrorContains(t, err, "reading config file")
+}
+
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, "log:\n  level: info\n")
+	_, err := Load(Options{Path: path})
+	assert.Nil(t, err)
+
+	var events []ChangeEvent
+	unsubscribe := Subscribe(func(e ChangeEvent) { events = append(events, e) })
+	defer unsubscribe()
+	// latest stays subscribed and tracks the configuration subscribers see
+	var latest Config
+	defer Subscribe(func(e ChangeEvent) { latest = e.New })()
+
+	writeConfig(t, path, "log:\n  level: debug\nserver:\n  requestTimeout: 3s\n  corsOrigins: [\"https://example.com\"]\n")
+	assert.Nil(t, Reload())
+	assert.Len(t, events, 1)
+	assert.Equal(t, "info", events[0].Old.Log.Level)
+	assert.Equal(t, "debug", latest.Log.Level)
+	assert.Equal(t, 3*time.Second, latest.Server.RequestTimeout)
+	assert.Equal(t, []string{"https://example.com"}, latest.Server.CORSOrigins)
+
+	// Unchanged files do not publish events
+	assert.Nil(t, Reload())
+	assert.Len(t, events, 1)
+
+	// Invalid values and malformed files are rejected and the current config kept
+	writeConfig(t, path, "log:\n  level: loud\nserver:\n  corsOrigins: [\"example.com\"]\n")
+	err = Reload()
+	assert.ErrorContains(t, err, "log.level")
+	assert.ErrorContains(t, err, "server.corsOrigins")
+	writeConfig(t, path, "log: [unclosed\n")
+	assert.NotNil(t, Reload())
+	assert.Len(t, events, 1)
+	assert.Equal(t, "debug", latest.Log.Level)
+
+	// Unsubscribed callbacks are no longer called
+	unsubscribe()
+	writeConfig(t, path, "log:\n  level: warn\n")
+	assert.Nil(t, Reload())
+	assert.Len(t, events, 1)
+	assert.Equal(t, "warn", latest.Log.Level)
+	assert.Equal(t, "debug", events[0].New.Log.Level)
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "app.yaml")
+	writeConfig(t, base, "app:\n  name: base\n  port: 9100\nlog:\n  level: warn\n  format: text\ndatabase:\n  dsn: base.db\n")
+	writeConfig(t, filepath.Join(dir, "app.dev.yaml"), "app:\n  port: 9200\nlog:\n  level: debug\ndatabase:\n  dsn: dev.db\n")
+	t.Setenv("GINAPP_APP_PORT", "9300")
+	t.Setenv("GINAPP_LOG_LEVEL", "error")
+
+	flags := NewFlagSet()
+	assert.Nil(t, flags.Parse([]string{"migrate", "--config", base, "--profile=dev", "up", "--port", "9400"}))
+	assert.Equal(t, []string{"migrate", "up"}, flags.Args())
+
+	cfg, err := Load(Options{Flags: flags})
+	assert.Nil(t, err)
+	assert.Equal(t, 9400, cfg.App.Port)            // flag over env
+	assert.Equal(t, "error", cfg.Log.Level)        // env over profile
+	assert.Equal(t, "dev.db", cfg.Database.DSN)    // profile over base
+	assert.Equal(t, "text", cfg.Log.Format)        // base over defaults
+	assert.Equal(t, "base", cfg.App.Name)          // base only
+	assert.Equal(t, "memory", cfg.Database.Driver) // defaults
+
+	// Reloads keep the profile overlay, env and flag overrides
+	var reloaded Config
+	unsubscribe := Subscribe(func(e ChangeEvent) { reloaded = e.New })
+	writeConfig(t, base, "app:\n  name: renamed\nlog:\n  format: text\ndatabase:\n  dsn: base.db\n")
+	assert.Nil(t, Reload())
+	unsubscribe()
+	assert.Equal(t, "renamed", reloaded.App.Name)
+	assert.Equal(t, "dev.db", reloaded.Database.DSN)
+	assert.Equal(t, "error", reloaded.Log.Level)
+	assert.Equal(t, 9400, reloaded.App.Port)
+
+	// Options are used when no flag is given; a missing profile file is an error
+	_, err = Load(Options{Path: base, Profile: "prod"})
+	assert.ErrorContains(t, err, "reading prod profile")
+
+	// Invalid overrides fail validation
+	t.Setenv("GINAPP_LOG_OUTPUT", "stdout,kafka")
+	_, err = Load(Options{Path: base})
+	assert.ErrorContains(t, err, "log.output[1].type \"kafka\" is unknown")
+}
+
+func TestLogSinks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, `log:
+  level: info
+  format: json
+  filename: app.log
+  output:
+    - type: stdout
+      format: text
+    - type: file
+      level: debug
+    - type: syslog
+      tag: gin-app
+  access:
+    filename: access.log
+`)
+	cfg, err := Load(Options{Path: path})
+	assert.Nil(t, err)
+	assert.Equal(t, []SinkConfig{
+		{Type: "stdout", Level: "info", Format: "text"},
+		{Type: "file", Level: "debug", Format: "json", Filename: "app.log"},
+		{Type: "syslog", Level: "info", Format: "json", Tag: "gin-app"},
+	}, cfg.Log.Sinks())
+	assert.Equal(t, "access.log", cfg.Log.Access.Filename)
+
+	// The single-string form still works, including comma-separated lists
+	writeConfig(t, path, "log:\n  output: stdout, file\n")
+	cfg, err = Load(Options{Path: path})
+	assert.Nil(t, err)
+	assert.Equal(t, []SinkConfig{{Type: "stdout"}, {Type: "file"}}, cfg.Log.Output)
+}
+
+func TestLoadAPIKeys(t *testing.T) {
+	const digest = "2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b"
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, "auth:\n  apiKeys:\n    - name: ci\n      sha256: "+digest+"\n")
+	cfg, err := Load(Options{Path: path})
+	assert.Nil(t, err)
+	assert.Equal(t, []APIKeyConfig{{Name: "ci", SHA256: digest}}, cfg.Auth.APIKeys)
+
+	writeConfig(t, path, "auth:\n  apiKeys:\n    - name: ci\n      sha256: "+digest+"\n    - name: ci\n      sha256: "+digest+"\n")
+	_, err = Load(Options{Path: path})
+	assert.ErrorContains(t, err, "auth.apiKeys[1].name \"ci\" must be set and unique")
+}
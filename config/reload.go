@@ -0,0 +1,218 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ChangeEvent 配置变更事件
+type ChangeEvent struct {
+	Old Config
+	New Config
+}
+
+type subscriber struct {
+	id int
+	fn func(ChangeEvent)
+}
+
+var (
+	mu          sync.RWMutex
+	active      *source // 最近一次Load使用的配置来源
+	current     Config  // 最近一次成功加载的配置，作为变更事件的Old
+	subscribers []subscriber
+	nextID      int
+	watchOnce   sync.Once
+)
+
+// Subscribe 注册配置变更订阅者，返回取消订阅函数
+// 订阅者在配置成功更新后按注册顺序同步调用
+func Subscribe(fn func(ChangeEvent)) (unsubscribe func()) {
+	mu.Lock()
+	defer mu.Unlock()
+	id := nextID
+	nextID++
+	subscribers = append(subscribers, subscriber{id: id, fn: fn})
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		for i, s := range subscribers {
+			if s.id == id {
+				subscribers = append(subscribers[:i:i], subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Watch 监听Load读取的配置文件变化并自动重新加载
+// 无效的配置会被拒绝并通过onError报告，当前配置保持不变
+func Watch(onError func(error)) {
+	mu.RLock()
+	src := active
+	mu.RUnlock()
+	if src == nil {
+		return
+	}
+
+	watchOnce.Do(func() {
+		src.v.OnConfigChange(func(fsnotify.Event) {
+			if err := Reload(); err != nil && onError != nil {
+				onError(err)
+			}
+		})
+		src.v.WatchConfig()
+	})
+}
+
+// Reload 按Load的配置来源重新读取配置，校验通过后替换当前配置并通知订阅者
+func Reload() error {
+	mu.RLock()
+	src := active
+	mu.RUnlock()
+	if src == nil {
+		return errors.New("config: Reload called before Load")
+	}
+
+	next, err := src.load()
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	old := current
+	if reflect.DeepEqual(old, next) {
+		// 编辑器保存时可能触发多次事件
+		mu.Unlock()
+		return nil
+	}
+	current = next
+	notify := subscribers
+	mu.Unlock()
+
+	event := ChangeEvent{Old: old, New: next}
+	for _, s := range notify {
+		s.fn(event)
+	}
+	return nil
+}
+
+// ValidationError 汇总配置校验发现的所有问题
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid config: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate 检查配置值是否有效，一次返回所有问题
+func (c Config) Validate() error {
+	var problems []string
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(c.App.Port > 0 && c.App.Port < 65536, "app.port %d is out of range", c.App.Port)
+	check(c.Server.RequestTimeout > 0, "server.requestTimeout must be positive")
+	check(len(c.Server.CORSOrigins) > 0, "server.corsOrigins must not be empty")
+	for _, origin := range c.Server.CORSOrigins {
+		check(origin == "*" || strings.HasPrefix(origin, "http://") || strings.HasPrefix(origin, "https://"),
+			"server.corsOrigins entry %q must be \"*\" or start with http:// or https://", origin)
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		check(validProxy(proxy), "server.trustedProxies entry %q must be an IP address or CIDR", proxy)
+	}
+	check(oneOf(c.Log.Level, logLevels...), "log.level %q is unknown", c.Log.Level)
+	check(oneOf(c.Log.Format, logFormats...), "log.format %q is unknown", c.Log.Format)
+	check(len(c.Log.Output) > 0, "log.output must not be empty")
+	for i, sink := range c.Log.Sinks() {
+		check(oneOf(sink.Type, "stdout", "file", "syslog"), "log.output[%d].type %q is unknown", i, sink.Type)
+		check(oneOf(sink.Level, logLevels...), "log.output[%d].level %q is unknown", i, sink.Level)
+		check(oneOf(sink.Format, logFormats...), "log.output[%d].format %q is unknown", i, sink.Format)
+		check(sink.Type != "file" || sink.Filename != "", "log.output[%d].filename must be set for file output", i)
+	}
+	check(c.Log.Access.Format == "" || oneOf(c.Log.Access.Format, logFormats...), "log.access.format %q is unknown", c.Log.Access.Format)
+	for i, pattern := range c.Log.Redact.Patterns {
+		_, err := regexp.Compile(pattern)
+		check(err == nil, "log.redact.patterns[%d] is not a valid regular expression: %v", i, err)
+	}
+	check(c.Log.Redact.Mask != "", "log.redact.mask must not be empty")
+	check(c.Log.MaxSize > 0, "log.maxSize must be positive")
+	check(c.Log.MaxBackups >= 0, "log.maxBackups must not be negative")
+	check(c.Log.MaxAge >= 0, "log.maxAge must not be negative")
+	check(oneOf(c.Database.Driver, "memory", "sqlite"), "database.driver %q is unknown", c.Database.Driver)
+	check(oneOf(c.Password.Algorithm, "bcrypt", "argon2id"), "password.algorithm %q is unknown", c.Password.Algorithm)
+	check(c.Password.BcryptCost == 0 || (c.Password.BcryptCost >= bcrypt.MinCost && c.Password.BcryptCost <= bcrypt.MaxCost),
+		"password.bcryptCost %d must be between %d and %d", c.Password.BcryptCost, bcrypt.MinCost, bcrypt.MaxCost)
+	check(c.Password.Argon2.Time > 0, "password.argon2.time must be positive")
+	check(c.Password.Argon2.Memory > 0, "password.argon2.memory must be positive")
+	check(c.Password.Argon2.Threads > 0, "password.argon2.threads must be positive")
+	check(c.Password.Argon2.KeyLength > 0, "password.argon2.keyLength must be positive")
+	check(c.Auth.AccessTokenTTL > 0, "auth.accessTokenTTL must be positive")
+	check(c.Auth.RefreshTokenTTL > 0, "auth.refreshTokenTTL must be positive")
+	apiKeyNames := map[string]bool{}
+	for i, key := range c.Auth.APIKeys {
+		check(key.Name != "" && !apiKeyNames[key.Name], "auth.apiKeys[%d].name %q must be set and unique", i, key.Name)
+		apiKeyNames[key.Name] = true
+		check(validSHA256(key.SHA256), "auth.apiKeys[%d].sha256 must be a hex-encoded SHA-256 digest", i)
+	}
+	check(oneOf(c.Tracing.Exporter, "none", "stdout", "file", "otlp"), "tracing.exporter %q is unknown", c.Tracing.Exporter)
+	check(c.Tracing.Exporter != "file" || c.Tracing.Filename != "", "tracing.filename must be set for file exporter")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sampleRatio %v must be between 0 and 1", c.Tracing.SampleRatio)
+	check(c.Health.Timeout > 0, "health.timeout must be positive")
+	check(c.Health.Interval >= 0, "health.interval must not be negative")
+	check(c.Health.ShutdownDelay >= 0, "health.shutdownDelay must not be negative")
+	for i, rule := range c.RateLimit.Rules {
+		check(rule.Route == "" || strings.HasPrefix(rule.Route, "/"), "rateLimit.rules[%d].route %q must start with /", i, rule.Route)
+		check(oneOf(rule.Key, "ip", "user", "api_key"), "rateLimit.rules[%d].key %q is unknown", i, rule.Key)
+		check(oneOf(rule.Algorithm, "token_bucket", "sliding_window"), "rateLimit.rules[%d].algorithm %q is unknown", i, rule.Algorithm)
+		check(rule.Limit > 0, "rateLimit.rules[%d].limit must be positive", i)
+		check(rule.Window > 0, "rateLimit.rules[%d].window must be positive", i)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+var (
+	logLevels  = []string{"trace", "debug", "info", "warn", "warning", "error", "fatal", "panic"}
+	logFormats = []string{"json", "text"}
+)
+
+// validSHA256 判断digest是否为十六进制编码的SHA-256摘要
+func validSHA256(digest string) bool {
+	decoded, err := hex.DecodeString(digest)
+	return err == nil && len(decoded) == sha256.Size
+}
+
+// validProxy 判断proxy是否为IP地址或CIDR
+func validProxy(proxy string) bool {
+	if _, _, err := net.ParseCIDR(proxy); err == nil {
+		return true
+	}
+	return net.ParseIP(proxy) != nil
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if strings.EqualFold(value, a) {
+			return true
+		}
+	}
+	return false
+}
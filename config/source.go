@@ -0,0 +1,157 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix 环境变量前缀，配置键中的 "." 替换为 "_"，例如 GINAPP_APP_PORT 覆盖 app.port
+const EnvPrefix = "GINAPP"
+
+// Options 指定配置来源
+// 优先级：命令行参数 > 环境变量 > profile文件 > 基础配置文件 > 默认值
+type Options struct {
+	Path    string         // 基础配置文件路径，为空时使用 GINAPP_CONFIG，再为空时查找当前目录下的 config.yaml
+	Profile string         // 配置档案，例如 dev 会叠加 config.dev.yaml；为空时使用 GINAPP_PROFILE
+	Flags   *pflag.FlagSet // 已解析的命令行参数（见NewFlagSet），只有显式传入的参数才会覆盖其他来源
+}
+
+// flagKeys 可直接通过命令行参数覆盖的配置项
+var flagKeys = map[string]string{
+	"port":            "app.port",
+	"log-level":       "log.level",
+	"database-driver": "database.driver",
+	"database-dsn":    "database.dsn",
+}
+
+// NewFlagSet 定义配置相关的命令行参数，解析后通过Options.Flags传给Load
+func NewFlagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("gin-app", pflag.ContinueOnError)
+	fs.String("config", "", "配置文件路径，默认为当前目录下的 config.yaml（环境变量 "+EnvPrefix+"_CONFIG）")
+	fs.String("profile", "", "配置档案，在基础配置上叠加 config.<profile>.yaml（环境变量 "+EnvPrefix+"_PROFILE）")
+	fs.Int("port", 0, "HTTP监听端口（app.port）")
+	fs.String("log-level", "", "日志级别（log.level）")
+	fs.String("database-driver", "", "存储类型 memory 或 sqlite（database.driver）")
+	fs.String("database-dsn", "", "sqlite数据库文件路径（database.dsn）")
+	return fs
+}
+
+// source 记录一次Load使用的配置来源，热更新时按相同来源重新读取
+type source struct {
+	v        *viper.Viper
+	explicit bool   // 是否显式指定了配置文件路径
+	profile  string // 配置档案，为空时不叠加
+}
+
+// Load 按Options读取并校验配置，返回的配置由调用方持有
+// 成功后记录配置来源供Reload和Watch重新读取，并作为下一次变更事件的Old
+func Load(opts Options) (*Config, error) {
+	v := viper.New()
+	setDefaults(v)
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	path := firstNonEmpty(flagValue(opts.Flags, "config"), opts.Path, os.Getenv(EnvPrefix+"_CONFIG"))
+	profile := firstNonEmpty(flagValue(opts.Flags, "profile"), opts.Profile, os.Getenv(EnvPrefix+"_PROFILE"))
+	if path != "" {
+		v.SetConfigFile(path)
+	} else {
+		v.SetConfigName("config")
+		v.SetConfigType("yaml")
+		v.AddConfigPath(".")
+	}
+
+	// 未显式传入的参数不会覆盖其他来源
+	if opts.Flags != nil {
+		for name, key := range flagKeys {
+			if f := opts.Flags.Lookup(name); f != nil {
+				if err := v.BindPFlag(key, f); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	src := &source{v: v, explicit: path != "", profile: profile}
+	cfg, err := src.load()
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	active = src
+	current = cfg
+	mu.Unlock()
+	return &cfg, nil
+}
+
+// load 读取配置文件，解析并校验配置
+func (s *source) load() (Config, error) {
+	var cfg Config
+	if err := s.read(); err != nil {
+		return cfg, err
+	}
+	if err := unmarshal(s.v, &cfg); err != nil {
+		return cfg, fmt.Errorf("decoding config: %w", err)
+	}
+	return cfg, cfg.Validate()
+}
+
+// read 读取基础配置文件并叠加profile文件
+func (s *source) read() error {
+	if err := s.v.ReadInConfig(); err != nil {
+		// 未指定路径时允许没有配置文件，只使用默认值和环境变量
+		var notFound viper.ConfigFileNotFoundError
+		if s.explicit || !errors.As(err, &notFound) {
+			return fmt.Errorf("reading config file: %w", err)
+		}
+	}
+	if s.profile == "" {
+		return nil
+	}
+
+	// 用单独的实例读取profile文件，热更新仍然监听基础配置文件
+	overlay := viper.New()
+	overlay.SetConfigFile(s.profilePath())
+	if err := overlay.ReadInConfig(); err != nil {
+		return fmt.Errorf("reading %s profile: %w", s.profile, err)
+	}
+	return s.v.MergeConfigMap(overlay.AllSettings())
+}
+
+// profilePath 返回与基础配置文件同目录的profile文件，例如 config.yaml -> config.dev.yaml
+func (s *source) profilePath() string {
+	base := s.v.ConfigFileUsed()
+	if base == "" {
+		base = "config.yaml"
+	}
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "." + s.profile + ext
+}
+
+// flagValue 返回显式传入的命令行参数值
+func flagValue(fs *pflag.FlagSet, name string) string {
+	if fs == nil {
+		return ""
+	}
+	if f := fs.Lookup(name); f != nil && f.Changed {
+		return f.Value.String()
+	}
+	return ""
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
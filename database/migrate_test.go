@@ -0,0 +1,77 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigratorUpDownStatus(t *testing.T) {
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "app.db"))
+	assert.Nil(t, err)
+	defer db.Close()
+
+	migrator, err := NewMigrator(db)
+	assert.Nil(t, err)
+	total := len(migrator.migrations)
+	assert.True(t, total > 0)
+
+	statuses, err := migrator.Status()
+	assert.Nil(t, err)
+	for _, s := range statuses {
+		assert.False(t, s.Applied)
+	}
+
+	applied, err := migrator.Up()
+	assert.Nil(t, err)
+	assert.Equal(t, total, applied)
+
+	// Running again is a no-op
+	applied, err = migrator.Up()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, applied)
+
+	statuses, err = migrator.Status()
+	assert.Nil(t, err)
+	for _, s := range statuses {
+		assert.True(t, s.Applied)
+		assert.False(t, s.AppliedAt.IsZero())
+	}
+
+	_, err = db.Exec("INSERT INTO users (id, username, email, created_at, updated_at) VALUES ('1', 'u', 'u@example.com', '', '')")
+	assert.Nil(t, err)
+
+	rolledBack, err := migrator.Down(total)
+	assert.Nil(t, err)
+	assert.Equal(t, total, rolledBack)
+
+	_, err = db.Exec("SELECT 1 FROM users")
+	assert.NotNil(t, err)
+}
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0002_second.up.sql":   {Data: []byte("B")},
+		"m/0002_second.down.sql": {Data: []byte("b")},
+		"m/0001_first.up.sql":    {Data: []byte("A")},
+		"m/0001_first.down.sql":  {Data: []byte("a")},
+		"m/README.md":            {Data: []byte("ignored")},
+	}
+	migrations, err := loadMigrations(fsys, "m")
+	assert.Nil(t, err)
+	assert.Len(t, migrations, 2)
+	assert.Equal(t, 1, migrations[0].Version)
+	assert.Equal(t, "first", migrations[0].Name)
+	assert.Equal(t, "A", migrations[0].Up)
+	assert.Equal(t, "b", migrations[1].Down)
+
+	// Missing down file
+	_, err = loadMigrations(fstest.MapFS{"m/0001_first.up.sql": {Data: []byte("A")}}, "m")
+	assert.NotNil(t, err)
+
+	// Non-numeric version
+	_, err = loadMigrations(fstest.MapFS{"m/abc_first.up.sql": {Data: []byte("A")}}, "m")
+	assert.NotNil(t, err)
+}
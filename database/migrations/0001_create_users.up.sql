@@ -0,0 +1,11 @@
+-- IF NOT EXISTS lets databases created before migrations were introduced adopt this baseline
+CREATE TABLE IF NOT EXISTS users (
+	id         TEXT PRIMARY KEY,
+	username   TEXT NOT NULL,
+	email      TEXT NOT NULL,
+	password   TEXT NOT NULL DEFAULT '',
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users (username);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
@@ -0,0 +1,2 @@
+-- Existing users become regular users; admins are granted explicitly
+ALTER TABLE users ADD COLUMN roles TEXT NOT NULL DEFAULT 'user';
@@ -0,0 +1,33 @@
+package database
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+
+	_ "modernc.org/sqlite" // SQLite driver (pure Go, no cgo required)
+)
+
+// OpenSQLite opens (or creates) the SQLite database at dsn, creating its
+// parent directory if needed
+func OpenSQLite(dsn string) (*sql.DB, error) {
+	if dir := filepath.Dir(dsn); dsn != ":memory:" && dir != "." {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite serializes writers; a single connection avoids "database is locked"
+	// errors and keeps ":memory:" databases shared across calls
+	db.SetMaxOpenConns(1)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
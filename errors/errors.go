@@ -1,15 +1,21 @@
 package errors
 
 import (
-	"fmt"
 	"net/http"
+
+	"gin-app/i18n"
+	"gin-app/responses"
 )
 
 // AppError 是应用程序的自定义错误类型
 type AppError struct {
-	StatusCode int               // HTTP状态码
-	Message    string            // 错误消息
-	Details    map[string]string // 可选的详细信息
+	StatusCode int                   // HTTP状态码
+	Message    string                // 错误消息（默认语言）
+	MessageID  string                // 可选的i18n消息ID，响应时翻译为请求的语言
+	Args       []interface{}         // MessageID的格式化参数
+	Details    map[string]string     // 可选的详细信息
+	DetailIDs  bool                  // Details的值是否为i18n消息ID，是则响应时翻译
+	Fields     responses.FieldErrors // 可选的字段级验证错误
 }
 
 // Error 实现错误接口
@@ -17,19 +23,53 @@ func (e *AppError) Error() string {
 	return e.Message
 }
 
-// 创建新的应用程序错误
+// Data 返回写入响应的详细信息，字段级验证错误优先于Details
+func (e *AppError) Data() interface{} {
+	if len(e.Fields) > 0 {
+		return e.Fields
+	}
+	if e.DetailIDs && e.Details != nil {
+		return responses.DetailIDs(e.Details)
+	}
+	return e.Details
+}
+
+// LocalizedMessage 返回翻译为lang的错误消息
+func (e *AppError) LocalizedMessage(lang string) string {
+	if e.MessageID == "" {
+		return e.Message
+	}
+	return i18n.Translate(lang, e.MessageID, e.Args...)
+}
+
+// 创建新的应用程序错误，message可以是i18n消息ID
 func NewAppError(statusCode int, message string, details map[string]string) *AppError {
-	return &AppError{
+	return newLocalizedError(statusCode, details, message)
+}
+
+// NewLocalizedError 创建消息为i18n消息ID的应用程序错误，args在响应时按请求的语言格式化
+func NewLocalizedError(statusCode int, id string, args ...interface{}) *AppError {
+	return newLocalizedError(statusCode, nil, id, args...)
+}
+
+// newLocalizedError 创建消息可翻译的应用程序错误；id不是已知消息ID时按普通文本处理
+func newLocalizedError(statusCode int, details map[string]string, id string, args ...interface{}) *AppError {
+	appErr := &AppError{
 		StatusCode: statusCode,
-		Message:    message,
+		Message:    i18n.Translate(i18n.Default, id, args...),
 		Details:    details,
 	}
+	if i18n.Has(id) {
+		appErr.MessageID = id
+		appErr.Args = args
+	}
+	return appErr
 }
 
 // NotFound 创建404错误
 func NotFound(message string, details map[string]string) *AppError {
 	if message == "" {
-		message = "Resource not found"
+		message = "error.not_found"
 	}
 	return NewAppError(http.StatusNotFound, message, details)
 }
@@ -37,7 +77,7 @@ func NotFound(message string, details map[string]string) *AppError {
 // BadRequest 创建400错误
 func BadRequest(message string, details map[string]string) *AppError {
 	if message == "" {
-		message = "Bad request"
+		message = "error.bad_request"
 	}
 	return NewAppError(http.StatusBadRequest, message, details)
 }
@@ -45,7 +85,7 @@ func BadRequest(message string, details map[string]string) *AppError {
 // Internal 创建500错误
 func Internal(message string, details map[string]string) *AppError {
 	if message == "" {
-		message = "Internal server error"
+		message = "error.internal"
 	}
 	return NewAppError(http.StatusInternalServerError, message, details)
 }
@@ -53,15 +93,24 @@ func Internal(message string, details map[string]string) *AppError {
 // Unauthorized 创建401错误
 func Unauthorized(message string, details map[string]string) *AppError {
 	if message == "" {
-		message = "Unauthorized"
+		message = "error.unauthorized"
 	}
 	return NewAppError(http.StatusUnauthorized, message, details)
 }
 
-// ValidationError 创建带有字段验证错误的400错误
-func ValidationError(field, message string) *AppError {
+// ValidationError 创建带有字段验证错误的400错误，id为字段消息的i18n消息ID，响应时翻译
+func ValidationError(field, id string) *AppError {
 	details := map[string]string{
-		field: message,
+		field: id,
 	}
-	return BadRequest(fmt.Sprintf("Validation error on field '%s'", field), details)
+	appErr := newLocalizedError(http.StatusBadRequest, details, "error.validation_field", field)
+	appErr.DetailIDs = true
+	return appErr
+}
+
+// FieldValidationError 创建单个字段验证失败的400错误，字段消息id在响应时按请求的语言翻译
+func FieldValidationError(field, code, id string, args ...interface{}) *AppError {
+	appErr := BadRequest("error.validation", nil)
+	appErr.Fields = responses.FieldErrors{newFieldError(field, code, id, args...)}
+	return appErr
 }
@@ -47,3 +47,17 @@ func TestValidationError(t *testing.T) {
 	assert.Equal(t, "Validation error on field 'field'", err.Message)
 	assert.Equal(t, map[string]string{"field": "test validation error"}, err.Details)
 }
+
+func TestNewLocalizedError(t *testing.T) {
+	err := NewLocalizedError(415, "patch.unsupported_format", "application/merge-patch+json")
+	assert.Equal(t, 415, err.StatusCode)
+	assert.Equal(t, "Unsupported patch format; use application/merge-patch+json", err.Message)
+	assert.Equal(t, "不支持的补丁格式，请使用 application/merge-patch+json", err.LocalizedMessage("zh-CN"))
+}
+
+func TestFieldValidationError(t *testing.T) {
+	err := FieldValidationError("email", "required", "validation.cannot_remove", "email")
+	assert.Equal(t, 400, err.StatusCode)
+	assert.Equal(t, "validation.cannot_remove", err.Fields[0].MessageID)
+	assert.Equal(t, "email cannot be removed", err.Fields[0].Message)
+}
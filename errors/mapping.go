@@ -0,0 +1,71 @@
+package errors
+
+import (
+	stderrors "errors"
+	"sync"
+)
+
+// Detailer 由能够提供详细信息的错误实现，其结果优先于映射表中的静态Details，
+// 其中可能包含客户端提供的值，响应时不做翻译
+type Detailer interface {
+	Details() map[string]string
+}
+
+// Mapping 描述一个哨兵错误对应的HTTP响应
+type Mapping struct {
+	Err        error             // 通过errors.Is匹配的哨兵错误
+	StatusCode int               // HTTP状态码
+	Message    string            // 返回给客户端的错误消息，可以是i18n消息ID
+	Details    map[string]string // 可选的详细信息，值为i18n消息ID
+}
+
+// Mapper 是哨兵错误到HTTP响应的映射表，按注册顺序匹配
+// 由启动代码创建并显式注册各包的映射，再交给ErrorHandlerMiddleware使用
+type Mapper struct {
+	mu       sync.RWMutex
+	mappings []Mapping
+}
+
+// NewMapper 创建空的映射表
+func NewMapper() *Mapper {
+	return &Mapper{}
+}
+
+// Register 注册哨兵错误到HTTP响应的映射
+func (m *Mapper) Register(entries ...Mapping) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mappings = append(m.mappings, entries...)
+}
+
+// FromError 将任意错误转换为AppError
+// AppError原样返回；已注册的哨兵错误按映射表转换；其他错误返回false
+func (m *Mapper) FromError(err error) (*AppError, bool) {
+	if err == nil {
+		return nil, false
+	}
+
+	var appErr *AppError
+	if stderrors.As(err, &appErr) {
+		return appErr, true
+	}
+	if m == nil {
+		return nil, false
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, mapping := range m.mappings {
+		if !stderrors.Is(err, mapping.Err) {
+			continue
+		}
+		var detailer Detailer
+		if stderrors.As(err, &detailer) {
+			return NewAppError(mapping.StatusCode, mapping.Message, detailer.Details()), true
+		}
+		appErr := NewAppError(mapping.StatusCode, mapping.Message, mapping.Details)
+		appErr.DetailIDs = mapping.Details != nil
+		return appErr, true
+	}
+	return nil, false
+}
@@ -0,0 +1,58 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errTestMissing = stderrors.New("missing")
+
+type missingError struct{ id string }
+
+func (e *missingError) Error() string              { return "missing " + e.id }
+func (e *missingError) Is(target error) bool       { return target == errTestMissing }
+func (e *missingError) Details() map[string]string { return map[string]string{"id": e.id} }
+
+func TestFromError(t *testing.T) {
+	mapper := NewMapper()
+	mapper.Register(Mapping{Err: errTestMissing, StatusCode: http.StatusNotFound, Message: "Thing not found", Details: map[string]string{"kind": "thing"}})
+
+	appErr, ok := mapper.FromError(fmt.Errorf("lookup: %w", errTestMissing))
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+	assert.Equal(t, "Thing not found", appErr.Message)
+	assert.Equal(t, map[string]string{"kind": "thing"}, appErr.Details)
+	assert.True(t, appErr.DetailIDs)
+
+	// Typed errors supply their own details
+	appErr, ok = mapper.FromError(&missingError{id: "42"})
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{"id": "42"}, appErr.Details)
+	assert.False(t, appErr.DetailIDs)
+
+	// AppErrors pass through unchanged
+	original := BadRequest("bad", nil)
+	appErr, ok = mapper.FromError(fmt.Errorf("wrapped: %w", original))
+	assert.True(t, ok)
+	assert.Same(t, original, appErr)
+
+	_, ok = mapper.FromError(stderrors.New("unknown"))
+	assert.False(t, ok)
+	_, ok = mapper.FromError(nil)
+	assert.False(t, ok)
+}
+
+func TestNilMapperPassesAppErrorsThrough(t *testing.T) {
+	var mapper *Mapper
+	original := NotFound("", nil)
+	appErr, ok := mapper.FromError(original)
+	assert.True(t, ok)
+	assert.Same(t, original, appErr)
+
+	_, ok = mapper.FromError(errTestMissing)
+	assert.False(t, ok)
+}
@@ -0,0 +1,115 @@
+package errors
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"gin-app/i18n"
+	"gin-app/responses"
+)
+
+func init() {
+	// 让验证错误使用JSON/表单字段名，而不是Go结构体字段名
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(fieldName)
+	}
+}
+
+// BindingError 将ShouldBindJSON/ShouldBindQuery等绑定失败转换为400错误
+// 验证失败按字段给出规则代码（required、min、email等）和可读消息
+func BindingError(err error) *AppError {
+	var validationErrs validator.ValidationErrors
+	if stderrors.As(err, &validationErrs) {
+		fields := make(responses.FieldErrors, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			id, args := fieldMessage(fe)
+			fields = append(fields, newFieldError(fieldPath(fe), fe.Tag(), id, args...))
+		}
+		appErr := BadRequest("error.validation", nil)
+		appErr.Fields = fields
+		return appErr
+	}
+
+	// JSON类型不匹配也可以定位到字段
+	var typeErr *json.UnmarshalTypeError
+	if stderrors.As(err, &typeErr) && typeErr.Field != "" {
+		appErr := BadRequest("error.validation", nil)
+		appErr.Fields = responses.FieldErrors{
+			newFieldError(typeErr.Field, "type", "validation.type", typeErr.Type.Kind().String()),
+		}
+		return appErr
+	}
+
+	return newLocalizedError(http.StatusBadRequest, nil, "error.invalid_request", err.Error())
+}
+
+// newFieldError 创建消息可翻译的字段错误
+func newFieldError(field, code, id string, args ...interface{}) responses.FieldError {
+	return responses.FieldError{
+		Field:     field,
+		Code:      code,
+		Message:   i18n.Translate(i18n.Default, id, args...),
+		MessageID: id,
+		Args:      args,
+	}
+}
+
+// fieldName 返回结构体字段的json标签名，其次是form标签名
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// fieldPath 去掉命名空间中的顶层结构体名，例如 "CreateUserRequest.email" -> "email"
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return fe.Field()
+}
+
+// fieldMessage 返回验证规则对应的消息ID和参数
+func fieldMessage(fe validator.FieldError) (string, []interface{}) {
+	param := fe.Param()
+	switch fe.Tag() {
+	case "required":
+		return "validation.required", nil
+	case "email":
+		return "validation.email", nil
+	case "min", "gte":
+		return "validation.min" + sizeUnit(fe), []interface{}{param}
+	case "max", "lte":
+		return "validation.max" + sizeUnit(fe), []interface{}{param}
+	case "len":
+		return "validation.len" + sizeUnit(fe), []interface{}{param}
+	case "oneof":
+		return "validation.oneof", []interface{}{strings.Join(strings.Fields(param), ", ")}
+	}
+	return "validation.rule", []interface{}{fe.Tag()}
+}
+
+// sizeUnit 返回长度类规则的消息ID后缀：字符串按字符数，集合按元素数
+func sizeUnit(fe validator.FieldError) string {
+	switch fe.Kind() {
+	case reflect.String:
+		return ".string"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return ".items"
+	}
+	return ""
+}
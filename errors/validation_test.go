@@ -0,0 +1,70 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+
+	"gin-app/responses"
+)
+
+type signupRequest struct {
+	Username string   `json:"username" binding:"required,min=3"`
+	Email    string   `json:"email" binding:"required,email"`
+	Age      int      `json:"age" binding:"omitempty,min=18"`
+	Tags     []string `json:"tags" binding:"omitempty,max=1"`
+	Sort     string   `form:"sort" binding:"omitempty,oneof=asc desc"`
+}
+
+// visible drops the translation metadata so tests compare what clients see
+func visible(fields responses.FieldErrors) responses.FieldErrors {
+	result := make(responses.FieldErrors, 0, len(fields))
+	for _, fe := range fields {
+		result = append(result, responses.FieldError{Field: fe.Field, Code: fe.Code, Message: fe.Message})
+	}
+	return result
+}
+
+func bindSignup(body string) error {
+	var req signupRequest
+	return binding.JSON.BindBody([]byte(body), &req)
+}
+
+func TestBindingErrorValidation(t *testing.T) {
+	err := bindSignup(`{"username":"al","email":"nope","age":3,"tags":["a","b"]}`)
+	appErr := BindingError(err)
+
+	assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+	assert.Equal(t, "Validation failed", appErr.Message)
+	assert.Equal(t, responses.FieldErrors{
+		{Field: "username", Code: "min", Message: "must be at least 3 characters"},
+		{Field: "email", Code: "email", Message: "must be a valid email address"},
+		{Field: "age", Code: "min", Message: "must be at least 18"},
+		{Field: "tags", Code: "max", Message: "must be at most 1 items"},
+	}, visible(appErr.Fields))
+
+	appErr = BindingError(bindSignup(`{"username":"alice","email":"a@example.com","sort":"up"}`))
+	assert.Equal(t, responses.FieldErrors{{Field: "sort", Code: "oneof", Message: "must be one of: asc, desc"}}, visible(appErr.Fields))
+
+	// The envelope renders field errors keyed by field name
+	data, _ := json.Marshal(BindingError(bindSignup(`{}`)).Data())
+	assert.JSONEq(t, `{
+		"username": {"code": "required", "message": "is required"},
+		"email": {"code": "required", "message": "is required"}
+	}`, string(data))
+}
+
+func TestBindingErrorMalformed(t *testing.T) {
+	appErr := BindingError(bindSignup(`{"username": 42}`))
+	assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+	assert.Equal(t, responses.FieldErrors{{Field: "username", Code: "type", Message: "must be a string"}}, visible(appErr.Fields))
+
+	appErr = BindingError(bindSignup(`{"username":`))
+	assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+	assert.True(t, strings.HasPrefix(appErr.Message, "Invalid request: "))
+	assert.Empty(t, appErr.Fields)
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	stderrors "errors"
+	"strings"
+	"time"
+
+	"gin-app/errors"
+	"gin-app/i18n"
+	"gin-app/log"
+	"gin-app/responses"
+	"gin-app/security"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// bearerClaimsKey is the gin.Context key under which the outcome of verifying
+// the request's bearer token is cached
+const bearerClaimsKey = "handler.bearerClaims"
+
+// errMissingBearer is returned by bearerClaims when the request has no bearer token
+var errMissingBearer = stderrors.New("missing bearer token")
+
+type bearerResult struct {
+	claims *security.Claims
+	err    error
+}
+
+// bearerClaims verifies the request's bearer token with tokens. The outcome is
+// cached in c, so RateLimitMiddleware and AuthMiddleware verify it only once
+func bearerClaims(c *gin.Context, tokens *security.TokenService) (*security.Claims, error) {
+	if cached, ok := c.Get(bearerClaimsKey); ok {
+		result := cached.(bearerResult)
+		return result.claims, result.err
+	}
+
+	var result bearerResult
+	scheme, token, found := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		result.err = errMissingBearer
+	} else {
+		result.claims, result.err = tokens.ParseAccessToken(strings.TrimSpace(token))
+	}
+	c.Set(bearerClaimsKey, result)
+	return result.claims, result.err
+}
+
+// AuthMiddleware requires a valid "Authorization: Bearer <access token>" header
+// and stores the caller's identity in the gin context
+func AuthMiddleware(tokens *security.TokenService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := bearerClaims(c, tokens)
+		if stderrors.Is(err, errMissingBearer) {
+			abortUnauthorized(c, "auth.missing_token")
+			return
+		}
+		if err != nil {
+			abortUnauthorized(c, "auth.invalid_token")
+			return
+		}
+
+		var expiresAt time.Time
+		if claims.ExpiresAt != nil {
+			expiresAt = claims.ExpiresAt.Time
+		}
+		security.SetIdentity(c, &security.Identity{
+			UserID:    claims.Subject,
+			Username:  claims.Username,
+			Roles:     claims.Roles,
+			TokenID:   claims.ID,
+			ExpiresAt: expiresAt,
+		})
+		log.AddFields(c, logrus.Fields{"user_id": claims.Subject})
+		c.Next()
+	}
+}
+
+// Authorize allows the request only if the authenticated caller satisfies
+// policy. It must run after AuthMiddleware
+func Authorize(policy security.Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, ok := security.CurrentIdentity(c)
+		if !ok {
+			abortUnauthorized(c, "auth.authentication_required")
+			return
+		}
+		if !policy.Allow(c, identity) {
+			responses.Forbidden(c, "error.forbidden")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// bearerChallenge is sent in WWW-Authenticate with every 401 response
+const bearerChallenge = `Bearer realm="api"`
+
+func abortUnauthorized(c *gin.Context, message string) {
+	appErr := errors.Unauthorized(message, nil)
+	c.Header("WWW-Authenticate", bearerChallenge)
+	responses.Error(c, appErr.StatusCode, appErr.LocalizedMessage(i18n.FromContext(c)), appErr.Details)
+	c.Abort()
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gin-app/models"
+	"gin-app/security"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	repo := models.NewInMemoryUserRepository()
+	user := &models.User{ID: "1", Username: "testuser", Email: "test@example.com"}
+	_ = repo.Create(user)
+	tokens := security.NewTokenService(security.TokenOptions{
+		Secret:          []byte("test-secret"),
+		Issuer:          "gin-app",
+		AccessTokenTTL:  time.Minute,
+		RefreshTokenTTL: time.Hour,
+	}, security.NewInMemoryRefreshTokenStore(), repo)
+
+	router := setupTestRouter()
+	router.GET("/me", AuthMiddleware(tokens), func(c *gin.Context) {
+		identity, _ := security.CurrentIdentity(c)
+		c.String(http.StatusOK, identity.Username)
+	})
+
+	// Missing header
+	req, _ := http.NewRequest("GET", "/me", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Contains(t, resp.Header().Get("WWW-Authenticate"), "Bearer")
+
+	// Invalid token
+	req, _ = http.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", "Bearer not-a-token")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	// Valid token
+	pair, _ := tokens.Issue(user)
+	req, _ = http.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "testuser", resp.Body.String())
+}
+
+func TestAuthorize(t *testing.T) {
+	router := setupTestRouter()
+	withIdentity := func(identity *security.Identity) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			if identity != nil {
+				security.SetIdentity(c, identity)
+			}
+		}
+	}
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	policy := security.SelfOr("id", models.PermissionUsersWrite)
+
+	router.PUT("/anonymous/:id", withIdentity(nil), Authorize(policy), ok)
+	router.PUT("/user/:id", withIdentity(&security.Identity{UserID: "1", Roles: []string{models.RoleUser}}), Authorize(policy), ok)
+	router.PUT("/admin/:id", withIdentity(&security.Identity{UserID: "2", Roles: []string{models.RoleAdmin}}), Authorize(policy), ok)
+
+	cases := []struct {
+		path string
+		code int
+	}{
+		{"/anonymous/1", http.StatusUnauthorized},
+		{"/user/1", http.StatusOK},
+		{"/user/2", http.StatusForbidden},
+		{"/admin/1", http.StatusOK},
+	}
+	for _, tc := range cases {
+		req, _ := http.NewRequest("PUT", tc.path, nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, tc.code, resp.Code, tc.path)
+	}
+}
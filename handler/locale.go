@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"gin-app/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LocaleMiddleware negotiates the response language from Accept-Language once
+// per request and stores it for i18n.FromContext
+func LocaleMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lang := i18n.Negotiate(c.GetHeader("Accept-Language"))
+		c.Set(i18n.ContextKey, lang)
+		c.Header("Content-Language", lang)
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Next()
+	}
+}
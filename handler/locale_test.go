@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	apperrors "gin-app/errors"
+	"gin-app/i18n"
+	"gin-app/responses"
+)
+
+func TestLocaleMiddleware(t *testing.T) {
+	errTaken := errors.New("taken")
+	mapper := apperrors.NewMapper()
+	mapper.Register(apperrors.Mapping{Err: errTaken, StatusCode: http.StatusConflict, Message: "user.username_taken",
+		Details: map[string]string{"username": "validation.already_taken"}})
+
+	router := setupTestRouter()
+	router.Use(LocaleMiddleware(), ErrorHandlerMiddleware(mapper))
+
+	router.GET("/missing", func(c *gin.Context) {
+		c.Error(apperrors.NotFound("user.not_found", nil))
+	})
+	router.GET("/missing/:id", func(c *gin.Context) {
+		c.Error(apperrors.NotFound("user.not_found", map[string]string{"id": c.Param("id")}))
+	})
+	router.GET("/taken", func(c *gin.Context) {
+		c.Error(errTaken)
+	})
+	router.POST("/signup", func(c *gin.Context) {
+		var req struct {
+			Email string `json:"email" binding:"required,email"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(apperrors.BindingError(err))
+			return
+		}
+		responses.Created(c, "user.created", nil)
+	})
+
+	request := func(method, path, body, lang string) (*httptest.ResponseRecorder, responses.Response) {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Accept-Language", lang)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		var envelope responses.Response
+		_ = json.Unmarshal(resp.Body.Bytes(), &envelope)
+		return resp, envelope
+	}
+
+	resp, envelope := request("GET", "/missing", "", "zh-CN,zh;q=0.9,en;q=0.8")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Equal(t, i18n.SimplifiedChinese, resp.Header().Get("Content-Language"))
+	assert.Equal(t, "用户不存在", envelope.Message)
+
+	_, envelope = request("GET", "/missing", "", "en-GB")
+	assert.Equal(t, "User not found", envelope.Message)
+
+	_, envelope = request("POST", "/signup", `{"email":"nope"}`, "zh-CN")
+	assert.Equal(t, "参数验证失败", envelope.Message)
+	assert.Equal(t, "必须是有效的邮箱地址", envelope.Data.(map[string]interface{})["email"].(map[string]interface{})["message"])
+
+	// Message IDs in mapped details are translated too...
+	_, envelope = request("GET", "/taken", "", "zh-CN")
+	assert.Equal(t, "用户名已被占用", envelope.Message)
+	assert.Equal(t, map[string]interface{}{"username": "已被占用"}, envelope.Data)
+	_, envelope = request("GET", "/taken", "", "en")
+	assert.Equal(t, map[string]interface{}{"username": "already taken"}, envelope.Data)
+
+	// ...but client-supplied values are written as they are, even if they look like one
+	for _, id := range []string{"validation.already_taken", "%d%s"} {
+		_, envelope = request("GET", "/missing/"+url.PathEscape(id), "", "zh-CN")
+		assert.Equal(t, map[string]interface{}{"id": id}, envelope.Data)
+	}
+
+	_, envelope = request("POST", "/signup", `{"email":"a@example.com"}`, "zh")
+	assert.Equal(t, "用户创建成功", envelope.Message)
+}
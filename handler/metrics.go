@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"gin-app/metrics"
+)
+
+// MetricsMiddleware records request count, latency and in-flight requests.
+// Requests are labeled by route template rather than raw path so that IDs in
+// the URL do not create a new time series per request
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = metrics.UnmatchedRoute
+		}
+		method := c.Request.Method
+
+		inFlight := metrics.RequestsInFlight.WithLabelValues(method, route)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		start := time.Now()
+		c.Next()
+
+		status := strconv.Itoa(c.Writer.Status())
+		metrics.RequestsTotal.WithLabelValues(method, route, status).Inc()
+		metrics.RequestDuration.WithLabelValues(method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"gin-app/metrics"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	router := setupTestRouter()
+	router.Use(MetricsMiddleware())
+
+	var inFlight float64
+	router.GET("/widgets/:id", func(c *gin.Context) {
+		inFlight = testutil.ToFloat64(metrics.RequestsInFlight.WithLabelValues("GET", "/widgets/:id"))
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	before := testutil.ToFloat64(metrics.RequestsTotal.WithLabelValues("GET", "/widgets/:id", "204"))
+	unmatchedBefore := testutil.ToFloat64(metrics.RequestsTotal.WithLabelValues("GET", metrics.UnmatchedRoute, "404"))
+
+	for _, path := range []string{"/widgets/1", "/widgets/2", "/nowhere/3"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// Requests are labeled by route template, not by raw path
+	assert.Equal(t, before+2, testutil.ToFloat64(metrics.RequestsTotal.WithLabelValues("GET", "/widgets/:id", "204")))
+	assert.Equal(t, unmatchedBefore+1, testutil.ToFloat64(metrics.RequestsTotal.WithLabelValues("GET", metrics.UnmatchedRoute, "404")))
+	assert.Equal(t, float64(1), inFlight)
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.RequestsInFlight.WithLabelValues("GET", "/widgets/:id")))
+
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/plain")
+
+	body := w.Body.String()
+	assert.Contains(t, body, `http_request_duration_seconds_bucket{method="GET",route="/widgets/:id",status="204",le="0.005"}`)
+	assert.Contains(t, body, "go_goroutines")
+	assert.Contains(t, body, "process_cpu_seconds_total")
+	assert.Contains(t, body, "users_created_total")
+	assert.Contains(t, body, `auth_logins_total{result="failure"}`)
+	assert.False(t, strings.Contains(body, "/widgets/1"), "raw paths must not become labels")
+}
@@ -2,37 +2,45 @@ package handler
 
 import (
 	"context"
-	stderrors "errors"
 	"gin-app/errors"
+	"gin-app/i18n"
 	"gin-app/log"
+	"gin-app/requestid"
 	"gin-app/responses"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// ErrorHandlerMiddleware catches AppError types and returns standardized responses
-func ErrorHandlerMiddleware() gin.HandlerFunc {
+// ErrorHandlerMiddleware turns errors added with c.Error into standardized responses.
+// AppErrors and sentinel errors registered on mapper keep their status code,
+// message and details; anything else is answered with a generic 500
+func ErrorHandlerMiddleware(mapper *errors.Mapper) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Next()
 
-		// Check if there are any errors
-		if len(c.Errors) > 0 {
-			for _, e := range c.Errors {
-				// Check if it's our custom AppError type
-				var appErr *errors.AppError
-				if stderrors.As(e.Err, &appErr) {
-					// Use the status code and message from our custom error
-					responses.Error(c, appErr.StatusCode, appErr.Error(), nil)
-					return
+		// Nothing to do if there are no errors or the handler already responded
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		for _, e := range c.Errors {
+			if appErr, ok := mapper.FromError(e.Err); ok {
+				if appErr.StatusCode == http.StatusUnauthorized {
+					c.Header("WWW-Authenticate", bearerChallenge)
 				}
+				responses.Error(c, appErr.StatusCode, appErr.LocalizedMessage(i18n.FromContext(c)), appErr.Data())
+				return
 			}
-			// If it's not our custom error, return a generic 500 error
-			responses.InternalServerError(c, http.StatusText(http.StatusInternalServerError), nil)
 		}
+
+		// If none of the errors is known, return a generic 500 error
+		responses.InternalServerError(c, "error.internal_server_error", nil)
 	}
 }
 
@@ -40,8 +48,8 @@ func RecoveryMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if r := recover(); r != nil {
-				// Record detailed logs
-				log.Logger.WithFields(logrus.Fields{
+				// Record detailed logs; the redaction hook scrubs secrets from the panic value
+				log.FromContext(c).WithFields(logrus.Fields{
 					"path":       c.Request.URL.Path,
 					"method":     c.Request.Method,
 					"error":      r,
@@ -62,10 +70,28 @@ func LoggerMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Start timer
 		start := time.Now()
-		path := c.Request.URL.Path
-		method := c.Request.Method
-		clientIP := c.ClientIP()
-		userAgent := c.Request.UserAgent()
+
+		// Request-scoped fields: handlers log with them through log.FromContext(c)
+		// and may add more with log.AddFields, which end up in the access log too
+		fields := logrus.Fields{
+			"path":       c.Request.URL.Path,
+			"method":     c.Request.Method,
+			"client_ip":  c.ClientIP(),
+			"user_agent": c.Request.UserAgent(),
+			"request_id": requestid.FromContext(c.Request.Context()),
+		}
+		// Sensitive query parameters are masked by the log redaction rules
+		if query := c.Request.URL.RawQuery; query != "" {
+			fields["query"] = "?" + query
+		}
+		// Correlate logs with the request span started by otelgin; the trace ID
+		// comes from the incoming traceparent header when there is one
+		if sc := trace.SpanContextFromContext(c.Request.Context()); sc.IsValid() {
+			fields["trace_id"] = sc.TraceID().String()
+			fields["span_id"] = sc.SpanID().String()
+		}
+		ctx := log.NewContext(c.Request.Context(), fields)
+		c.Request = c.Request.WithContext(ctx)
 
 		// Process request
 		c.Next()
@@ -74,21 +100,23 @@ func LoggerMiddleware() gin.HandlerFunc {
 		duration := time.Since(start)
 		statusCode := c.Writer.Status()
 
-		// Create structured log entry with common fields
-		logEntry := log.Logger.WithFields(logrus.Fields{
-			"status":     statusCode,
-			"duration":   duration.String(),
-			"path":       path,
-			"method":     method,
-			"client_ip":  clientIP,
-			"user_agent": userAgent,
-			"request_id": c.GetHeader("X-Request-ID"),
+		// Create structured log entry with the request fields; requests go to the
+		// access log when log.access.filename is set
+		logEntry := log.AccessLogger().WithFields(log.Fields(ctx)).WithFields(logrus.Fields{
+			"status":   statusCode,
+			"duration": duration.String(),
 		})
 
-		// Log based on status code
+		// Expected errors such as "user not found" reach here through c.Error too,
+		// so attach them but log based on status code
 		if len(c.Errors) > 0 {
+			logEntry = logEntry.WithField("errors", c.Errors.String())
+		}
+
+		// Log based on status code
+		if statusCode >= http.StatusInternalServerError && len(c.Errors) > 0 {
 			// Log request with error details
-			logEntry.WithField("errors", c.Errors.String()).Error("Request failed with errors")
+			logEntry.Error("Request failed with errors")
 		} else if statusCode >= http.StatusBadRequest && statusCode < http.StatusInternalServerError {
 			// Client errors (4xx)
 			logEntry.Warn("Client error")
@@ -102,21 +130,52 @@ func LoggerMiddleware() gin.HandlerFunc {
 	}
 }
 
-// CORSMiddleware sets up Cross-Origin Resource Sharing
+// corsHandler holds the CORS handler built for the current allowed origins
+var corsHandler atomic.Value // gin.HandlerFunc
+
+func init() {
+	SetCORSOrigins([]string{"*"})
+}
+
+// CORSMiddleware sets up Cross-Origin Resource Sharing for the origins set with
+// SetCORSOrigins (all origins by default)
 func CORSMiddleware() gin.HandlerFunc {
-	return cors.New(cors.Config{
-		AllowOrigins:     []string{"*"}, // Allow all origins, should be restricted in production
+	return func(c *gin.Context) {
+		corsHandler.Load().(gin.HandlerFunc)(c)
+	}
+}
+
+// SetCORSOrigins replaces the allowed origins, e.g. after a config reload. "*"
+// allows all origins; other entries must start with http:// or https://
+func SetCORSOrigins(origins []string) {
+	cfg := cors.Config{
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", "If-Match", "If-None-Match", "X-API-Key"},
+		ExposeHeaders:    []string{"Content-Length", "ETag", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
-	})
+	}
+	for _, origin := range origins {
+		if origin == "*" {
+			cfg.AllowOrigins = []string{"*"} // Allow all origins, should be restricted in production
+			break
+		}
+		cfg.AllowOrigins = append(cfg.AllowOrigins, origin)
+	}
+	corsHandler.Store(cors.New(cfg))
 }
 
 // TimeoutMiddleware aborts requests that take too long to process
 func TimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
+	return DynamicTimeoutMiddleware(func() time.Duration { return timeout })
+}
+
+// DynamicTimeoutMiddleware is TimeoutMiddleware with the timeout looked up per
+// request, so it can change at runtime
+func DynamicTimeoutMiddleware(timeoutFunc func() time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		timeout := timeoutFunc()
+
 		// Create a context with timeout
 		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
 		defer cancel()
@@ -139,14 +198,9 @@ func TimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
 		case <-ctx.Done():
 			// Request timed out
 			if ctx.Err() == context.DeadlineExceeded {
-				log.Logger.WithFields(logrus.Fields{
-					"path":      c.Request.URL.Path,
-					"method":    c.Request.Method,
-					"client_ip": c.ClientIP(),
-					"timeout":   timeout.String(),
-				}).Warn("Request timed out")
-
-				responses.RequestTimeout(c, "Request timeout", nil) // Explicitly passing nil as data parameter
+				log.FromContext(c).WithField("timeout", timeout.String()).Warn("Request timed out")
+
+				responses.RequestTimeout(c, "error.request_timeout", nil) // Explicitly passing nil as data parameter
 				c.Abort()
 			}
 		}
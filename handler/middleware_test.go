@@ -2,6 +2,7 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 
 	"net/http"
 	"net/http/httptest"
@@ -9,7 +10,12 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
+
+	apperrors "gin-app/errors"
+	"gin-app/log"
 )
 
 // Setup test router
@@ -89,6 +95,35 @@ func TestLoggerMiddleware(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, resp.Code)
 }
 
+// Test fields added by handlers appear in the access log line
+func TestLoggerMiddlewareRequestFields(t *testing.T) {
+	hook := logtest.NewLocal(log.Logger)
+	defer hook.Reset()
+
+	router := setupTestRouter()
+	router.Use(RequestIDMiddleware(), LoggerMiddleware())
+	router.GET("/users/:id", func(c *gin.Context) {
+		log.AddFields(c, logrus.Fields{"target_id": c.Param("id")})
+		log.FromContext(c).Info("looking up user")
+		c.Status(http.StatusNoContent)
+	})
+
+	req, _ := http.NewRequest("GET", "/users/7", nil)
+	req.Header.Set("X-Request-ID", "01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := hook.AllEntries()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "looking up user", entries[0].Message)
+	assert.Equal(t, "01ARZ3NDEKTSV4RRFFQ69G5FAV", entries[0].Data["request_id"])
+	assert.Equal(t, "/users/7", entries[0].Data["path"])
+
+	access := entries[1]
+	assert.Equal(t, http.StatusNoContent, access.Data["status"])
+	assert.Equal(t, "01ARZ3NDEKTSV4RRFFQ69G5FAV", access.Data["request_id"])
+	assert.Equal(t, "7", access.Data["target_id"])
+}
+
 // Mock context for testing the error handler
 type mockContext struct {
 	*gin.Context
@@ -104,7 +139,7 @@ func TestErrorHandlerMiddleware(t *testing.T) {
 	router := setupTestRouter()
 
 	// Add error handler middleware
-	router.Use(ErrorHandlerMiddleware())
+	router.Use(ErrorHandlerMiddleware(apperrors.NewMapper()))
 
 	// Create a route that adds an error to the context
 	router.GET("/app-error", func(c *gin.Context) {
@@ -122,6 +157,56 @@ func TestErrorHandlerMiddleware(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, resp.Code)
 }
 
+// Test error handler middleware keeps the status code and details of known errors
+func TestErrorHandlerMiddlewareMapsKnownErrors(t *testing.T) {
+	router := setupTestRouter()
+	router.Use(ErrorHandlerMiddleware(apperrors.NewMapper()))
+
+	router.GET("/missing", func(c *gin.Context) {
+		c.Error(fmt.Errorf("lookup failed: %w", apperrors.NotFound("User not found", map[string]string{"id": "42"})))
+	})
+	router.GET("/handled", func(c *gin.Context) {
+		c.Error(errors.New("already reported"))
+		c.JSON(http.StatusAccepted, gin.H{"status": "accepted"})
+	})
+
+	req, _ := http.NewRequest("GET", "/missing", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"id":"42"`)
+
+	// Responses already written by the handler are left alone
+	req, _ = http.NewRequest("GET", "/handled", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+}
+
+// Test CORS origins can be replaced at runtime
+func TestSetCORSOrigins(t *testing.T) {
+	router := setupTestRouter()
+	router.Use(CORSMiddleware())
+	router.GET("/cors", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	defer SetCORSOrigins([]string{"*"})
+
+	request := func(origin string) string {
+		req, _ := http.NewRequest("GET", "/cors", nil)
+		req.Header.Set("Origin", origin)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp.Header().Get("Access-Control-Allow-Origin")
+	}
+
+	assert.NotEmpty(t, request("https://any.example"))
+
+	SetCORSOrigins([]string{"https://app.example"})
+	assert.Equal(t, "https://app.example", request("https://app.example"))
+	assert.Empty(t, request("https://any.example"))
+}
+
 // Test timeout middleware aborts long requests
 func TestTimeoutMiddleware(t *testing.T) {
 	router := setupTestRouter()
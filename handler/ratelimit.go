@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"gin-app/log"
+	"gin-app/metrics"
+	"gin-app/ratelimit"
+	"gin-app/responses"
+	"gin-app/security"
+)
+
+// Keys a RateLimitRule can count requests by
+const (
+	RateLimitByIP     = "ip"
+	RateLimitByUser   = "user"    // authenticated user; anonymous requests fall back to the client IP
+	RateLimitByAPIKey = "api_key" // X-API-Key header; requests without a known key fall back to the client IP
+)
+
+// APIKeyHeader carries the API key used by RateLimitByAPIKey
+const APIKeyHeader = "X-API-Key"
+
+// RateLimitRule limits the requests matching Method and Route, counted per Key
+type RateLimitRule struct {
+	// Name separates the quotas of different rules; defaults to "Method Route"
+	Name string
+	// Method matches the request method; empty matches every method
+	Method string
+	// Route matches the route template (c.FullPath). A trailing "/*" matches
+	// the prefix, and an empty Route matches every route
+	Route string
+	Key   string
+	ratelimit.Policy
+}
+
+// rateLimitRules holds the rules set with SetRateLimitRules
+var rateLimitRules atomic.Pointer[[]RateLimitRule]
+
+// SetRateLimitRules replaces the rate limit rules, e.g. after a config reload.
+// The first rule matching a request applies; no rules disables rate limiting
+func SetRateLimitRules(rules []RateLimitRule) {
+	rules = append([]RateLimitRule(nil), rules...)
+	for i := range rules {
+		if rules[i].Name == "" {
+			rules[i].Name = strings.TrimSpace(rules[i].Method + " " + rules[i].Route)
+		}
+	}
+	rateLimitRules.Store(&rules)
+}
+
+// RateLimitMiddleware enforces the rules set with SetRateLimitRules using store.
+// It answers with the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset
+// headers, and with 429 and Retry-After once a quota is used up. Since it runs
+// before AuthMiddleware, tokens is used to find the user for RateLimitByUser;
+// the verified token is cached for AuthMiddleware rather than checked twice.
+// RateLimitByAPIKey only counts keys known to apiKeys, so made-up keys cannot
+// open new quotas. If the store fails, the request is let through
+func RateLimitMiddleware(store ratelimit.Store, tokens *security.TokenService, apiKeys security.APIKeyStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rule, ok := matchRateLimitRule(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		result, err := store.Allow(rule.Name+"|"+rateLimitKey(c, rule.Key, tokens, apiKeys), rule.Policy)
+		if err != nil {
+			log.FromContext(c).WithError(err).WithField("rule", rule.Name).Error("rate limit store failed; allowing request")
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+		c.Header("RateLimit-Policy", strconv.Itoa(rule.Limit)+";w="+strconv.Itoa(ceilSeconds(rule.Window)))
+
+		if !result.Allowed {
+			retryAfter := ceilSeconds(result.RetryAfter)
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			metrics.RateLimited.WithLabelValues(rule.Name).Inc()
+			responses.TooManyRequests(c, "error.rate_limited", map[string]int{"retry_after": retryAfter})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// matchRateLimitRule returns the first rule matching the request
+func matchRateLimitRule(c *gin.Context) (RateLimitRule, bool) {
+	rules := rateLimitRules.Load()
+	if rules == nil {
+		return RateLimitRule{}, false
+	}
+	route := c.FullPath()
+	for _, rule := range *rules {
+		if rule.Method != "" && !strings.EqualFold(rule.Method, c.Request.Method) {
+			continue
+		}
+		if prefix, ok := strings.CutSuffix(rule.Route, "/*"); ok {
+			if route == prefix || strings.HasPrefix(route, prefix+"/") {
+				return rule, true
+			}
+		} else if rule.Route == "" || rule.Route == route {
+			return rule, true
+		}
+	}
+	return RateLimitRule{}, false
+}
+
+// rateLimitKey identifies the caller a request is counted against
+func rateLimitKey(c *gin.Context, key string, tokens *security.TokenService, apiKeys security.APIKeyStore) string {
+	switch key {
+	case RateLimitByUser:
+		if identity, ok := security.CurrentIdentity(c); ok {
+			return "user:" + identity.UserID
+		}
+		if tokens != nil {
+			if claims, err := bearerClaims(c, tokens); err == nil {
+				return "user:" + claims.Subject
+			}
+		}
+	case RateLimitByAPIKey:
+		if apiKeys != nil {
+			if name, ok := apiKeys.Lookup(c.GetHeader(APIKeyHeader)); ok {
+				return "key:" + name
+			}
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// ceilSeconds rounds d up to whole seconds, as required by the headers
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
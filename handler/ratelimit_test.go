@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"gin-app/models"
+	"gin-app/ratelimit"
+	"gin-app/security"
+)
+
+type failingStore struct{}
+
+func (failingStore) Allow(string, ratelimit.Policy) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("store unavailable")
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	repo := models.NewInMemoryUserRepository()
+	user := &models.User{ID: "1", Username: "testuser", Email: "test@example.com"}
+	_ = repo.Create(user)
+	tokens := security.NewTokenService(security.TokenOptions{
+		Secret:          []byte("test-secret"),
+		Issuer:          "gin-app",
+		AccessTokenTTL:  time.Minute,
+		RefreshTokenTTL: time.Hour,
+	}, security.NewInMemoryRefreshTokenStore(), repo)
+	pair, _ := tokens.Issue(user)
+	digest := func(key string) string {
+		sum := sha256.Sum256([]byte(key))
+		return hex.EncodeToString(sum[:])
+	}
+	apiKeys, err := security.NewStaticAPIKeyStore(map[string]string{"ci": digest("key-1"), "cd": digest("key-2")})
+	assert.Nil(t, err)
+
+	SetRateLimitRules([]RateLimitRule{
+		{Method: "POST", Route: "/login", Key: RateLimitByIP, Policy: ratelimit.Policy{Algorithm: ratelimit.SlidingWindow, Limit: 2, Window: time.Minute}},
+		{Name: "users", Route: "/users/*", Key: RateLimitByUser, Policy: ratelimit.Policy{Algorithm: ratelimit.TokenBucket, Limit: 1, Window: time.Minute}},
+		{Name: "api", Route: "/api", Key: RateLimitByAPIKey, Policy: ratelimit.Policy{Algorithm: ratelimit.TokenBucket, Limit: 1, Window: time.Minute}},
+	})
+	defer SetRateLimitRules(nil)
+
+	router := setupTestRouter()
+	router.Use(RateLimitMiddleware(ratelimit.NewInMemoryStore(), tokens, apiKeys))
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	router.POST("/login", ok)
+	router.GET("/login", ok)
+	router.GET("/users", ok)
+	router.GET("/users/:id", ok)
+	router.GET("/api", ok)
+
+	request := func(method, path string, header ...string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request("POST", "/login")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "2;w=60", w.Header().Get("RateLimit-Policy"))
+	assert.NotEmpty(t, w.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, http.StatusNoContent, request("POST", "/login").Code)
+
+	w = request("POST", "/login")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	var body struct {
+		Code    int            `json:"code"`
+		Message string         `json:"message"`
+		Data    map[string]int `json:"data"`
+	}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, http.StatusTooManyRequests, body.Code)
+	assert.Equal(t, "Too many requests, please try again later", body.Message)
+	assert.Greater(t, body.Data["retry_after"], 0)
+
+	// Rules only apply to matching methods and routes
+	w = request("GET", "/login")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+
+	// Users are counted by their token across the prefix; anonymous callers by IP
+	auth := []string{"Authorization", "Bearer " + pair.AccessToken}
+	assert.Equal(t, http.StatusNoContent, request("GET", "/users", auth...).Code)
+	assert.Equal(t, http.StatusTooManyRequests, request("GET", "/users/1", auth...).Code)
+	assert.Equal(t, http.StatusNoContent, request("GET", "/users/1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, request("GET", "/users/1", "Authorization", "Bearer forged").Code)
+
+	// Each issued API key has its own quota
+	assert.Equal(t, http.StatusNoContent, request("GET", "/api", APIKeyHeader, "key-1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, request("GET", "/api", APIKeyHeader, "key-1").Code)
+	assert.Equal(t, http.StatusNoContent, request("GET", "/api", APIKeyHeader, "key-2").Code)
+
+	// Made-up keys do not open new quotas; they share the caller's IP quota
+	assert.Equal(t, http.StatusNoContent, request("GET", "/api", APIKeyHeader, "made-up-1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, request("GET", "/api", APIKeyHeader, "made-up-2").Code)
+	assert.Equal(t, http.StatusTooManyRequests, request("GET", "/api").Code)
+}
+
+func TestRateLimitMiddlewareFailsOpen(t *testing.T) {
+	SetRateLimitRules([]RateLimitRule{{Key: RateLimitByIP, Policy: ratelimit.Policy{Algorithm: ratelimit.TokenBucket, Limit: 1, Window: time.Minute}}})
+	defer SetRateLimitRules(nil)
+
+	router := setupTestRouter()
+	router.Use(RateLimitMiddleware(failingStore{}, nil, nil))
+	router.GET("/ok", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	req, _ := http.NewRequest("GET", "/ok", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+// countingTokenStore counts access token verifications
+type countingTokenStore struct {
+	*security.InMemoryRefreshTokenStore
+	checks int
+}
+
+func (s *countingTokenStore) IsAccessTokenRevoked(tokenID string) (bool, error) {
+	s.checks++
+	return s.InMemoryRefreshTokenStore.IsAccessTokenRevoked(tokenID)
+}
+
+func TestRateLimitByUserVerifiesTokenOnce(t *testing.T) {
+	repo := models.NewInMemoryUserRepository()
+	user := &models.User{ID: "1", Username: "testuser", Email: "test@example.com"}
+	_ = repo.Create(user)
+	store := &countingTokenStore{InMemoryRefreshTokenStore: security.NewInMemoryRefreshTokenStore()}
+	tokens := security.NewTokenService(security.TokenOptions{
+		Secret:         []byte("test-secret"),
+		Issuer:         "gin-app",
+		AccessTokenTTL: time.Minute,
+	}, store, repo)
+	pair, _ := tokens.Issue(user)
+
+	SetRateLimitRules([]RateLimitRule{{Key: RateLimitByUser, Policy: ratelimit.Policy{Algorithm: ratelimit.TokenBucket, Limit: 5, Window: time.Minute}}})
+	defer SetRateLimitRules(nil)
+
+	router := setupTestRouter()
+	router.Use(RateLimitMiddleware(ratelimit.NewInMemoryStore(), tokens, nil))
+	router.GET("/me", AuthMiddleware(tokens), func(c *gin.Context) {
+		identity, _ := security.CurrentIdentity(c)
+		c.String(http.StatusOK, identity.UserID)
+	})
+
+	req, _ := http.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "4", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, 1, store.checks)
+
+	// A revoked token is turned away by AuthMiddleware without verifying it again
+	claims, _ := tokens.ParseAccessToken(pair.AccessToken)
+	assert.Nil(t, tokens.Revoke(&security.Identity{UserID: "1", TokenID: claims.ID, ExpiresAt: claims.ExpiresAt.Time}, ""))
+	store.checks = 0
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, 1, store.checks)
+}
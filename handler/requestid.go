@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"gin-app/requestid"
+)
+
+// RequestIDMiddleware accepts the client's X-Request-ID when it is a UUID or
+// ULID and generates a new one otherwise. The ID is echoed in the response
+// header and stored in the request context, where error responses and
+// LoggerMiddleware read it; the client's header is left as it was sent
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		c.Header(requestid.Header, id)
+		c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), id))
+		c.Next()
+	}
+}
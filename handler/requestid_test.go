@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+
+	apperrors "gin-app/errors"
+	"gin-app/log"
+	"gin-app/requestid"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	router := setupTestRouter()
+	router.Use(RequestIDMiddleware(), ErrorHandlerMiddleware(apperrors.NewMapper()))
+
+	var seen string
+	router.GET("/ok", func(c *gin.Context) {
+		seen = requestid.FromContext(c.Request.Context())
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/missing", func(c *gin.Context) {
+		c.Error(apperrors.NotFound("user.not_found", nil))
+	})
+
+	request := func(path, id string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		if id != "" {
+			req.Header.Set(requestid.Header, id)
+		}
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	// Valid client IDs are kept and echoed
+	const ulid = "01ARZ3NDEKTSV4RRFFQ69G5FAV"
+	resp := request("/ok", ulid)
+	assert.Equal(t, ulid, resp.Header().Get(requestid.Header))
+	assert.Equal(t, ulid, seen)
+
+	// Missing or malformed IDs are replaced with a generated UUID
+	for _, id := range []string{"", "not-an-id"} {
+		resp = request("/ok", id)
+		generated := resp.Header().Get(requestid.Header)
+		assert.True(t, requestid.Valid(generated), id)
+		assert.NotEqual(t, id, generated)
+		assert.Equal(t, generated, seen)
+	}
+
+	// Error envelopes carry the ID
+	resp = request("/missing", ulid)
+	var body map[string]interface{}
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &body))
+	assert.Equal(t, ulid, body["request_id"])
+}
+
+// TestRequestIDReachesAccessLog checks that the access log takes the ID from the
+// request context: the client's malformed header is left untouched, yet the
+// generated ID is logged
+func TestRequestIDReachesAccessLog(t *testing.T) {
+	hook := logtest.NewLocal(log.AccessLogger())
+	defer hook.Reset()
+
+	router := setupTestRouter()
+	router.Use(RequestIDMiddleware(), LoggerMiddleware())
+	var header string
+	router.GET("/ok", func(c *gin.Context) {
+		header = c.GetHeader(requestid.Header)
+		c.Status(http.StatusNoContent)
+	})
+
+	req, _ := http.NewRequest("GET", "/ok", nil)
+	req.Header.Set(requestid.Header, "not-an-id")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	generated := resp.Header().Get(requestid.Header)
+	assert.True(t, requestid.Valid(generated))
+	assert.Equal(t, "not-an-id", header)
+
+	entry := hook.LastEntry()
+	assert.NotNil(t, entry)
+	assert.Equal(t, generated, entry.Data["request_id"])
+}
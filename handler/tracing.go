@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"gin-app/models"
+)
+
+// tracerName identifies the handler spans below the request span started by
+// otelgin. The tracer is looked up per request so that it follows the provider
+// installed by tracing.Init
+const tracerName = "gin-app/handler"
+
+// Traced runs h in a span called name. The span becomes the parent of spans
+// started from c.Request.Context() inside h, such as repository calls, and
+// records the errors h adds with c.Error. Expected errors such as a missing user
+// are left out, as they are on repository spans
+func Traced(name string, h gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, span := otel.Tracer(tracerName).Start(c.Request.Context(), name)
+		defer span.End()
+
+		request := c.Request
+		c.Request = request.WithContext(ctx)
+		errorsBefore := len(c.Errors)
+
+		h(c)
+
+		// Later middleware (ErrorHandler, Logger) keep seeing the request span
+		c.Request = request
+
+		var failure error
+		for _, e := range c.Errors[errorsBefore:] {
+			if models.IsExpectedError(e.Err) {
+				continue
+			}
+			span.RecordError(e.Err)
+			failure = e.Err
+		}
+		if failure != nil {
+			span.SetStatus(codes.Error, failure.Error())
+		}
+		if c.Writer.Written() {
+			span.SetAttributes(attribute.Int("http.response.status_code", c.Writer.Status()))
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+// 支持的语言
+const (
+	English           = "en"
+	SimplifiedChinese = "zh-CN"
+)
+
+// Default 是Accept-Language无法匹配时使用的语言，也是缺失翻译时的回退语言
+const Default = English
+
+// ContextKey 是gin上下文中保存请求语言的键
+const ContextKey = "i18n.lang"
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// catalogs 按语言保存消息目录：消息ID -> 消息模板
+var catalogs = map[string]map[string]string{}
+
+// supported 与matcher中的语言顺序一致，第一个为默认语言
+var (
+	supported = []string{English, SimplifiedChinese}
+	matcher   language.Matcher
+)
+
+func init() {
+	tags := make([]language.Tag, 0, len(supported))
+	for _, lang := range supported {
+		data, err := localeFS.ReadFile(path.Join("locales", lang+".json"))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: missing catalog for %s: %v", lang, err))
+		}
+		catalog := map[string]string{}
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog for %s: %v", lang, err))
+		}
+		catalogs[lang] = catalog
+		tags = append(tags, language.MustParse(lang))
+	}
+	matcher = language.NewMatcher(tags)
+}
+
+// Languages 返回支持的语言列表
+func Languages() []string {
+	return append([]string(nil), supported...)
+}
+
+// Has 判断id是否为已知的消息ID
+func Has(id string) bool {
+	_, ok := catalogs[Default][id]
+	return ok
+}
+
+// Translate 返回消息id在lang中的翻译，并用args格式化
+// 缺失的翻译回退到默认语言；未知的id原样返回，因此普通文本也可以直接传入
+func Translate(lang, id string, args ...interface{}) string {
+	message, ok := catalogs[lang][id]
+	if !ok {
+		if message, ok = catalogs[Default][id]; !ok {
+			message = id
+		}
+	}
+	if len(args) > 0 && ok {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// Negotiate 根据Accept-Language头选择最合适的支持语言
+func Negotiate(acceptLanguage string) string {
+	if strings.TrimSpace(acceptLanguage) == "" {
+		return Default
+	}
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return Default
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return Default
+	}
+	return supported[index]
+}
+
+// FromContext 返回请求的语言：优先使用中间件保存的结果，否则直接协商Accept-Language
+func FromContext(c *gin.Context) string {
+	if lang := c.GetString(ContextKey); lang != "" {
+		return lang
+	}
+	if c.Request == nil {
+		return Default
+	}
+	return Negotiate(c.GetHeader("Accept-Language"))
+}
+
+// T 返回消息id在当前请求语言中的翻译
+func T(c *gin.Context, id string, args ...interface{}) string {
+	return Translate(FromContext(c), id, args...)
+}
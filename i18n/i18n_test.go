@@ -0,0 +1,68 @@
+package i18n
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogsAreComplete(t *testing.T) {
+	for _, lang := range Languages() {
+		assert.Len(t, catalogs[lang], len(catalogs[Default]), lang)
+		for id, message := range catalogs[Default] {
+			translated, ok := catalogs[lang][id]
+			if assert.True(t, ok, "%s is missing %s", lang, id) {
+				assert.Equal(t, strings.Count(message, "%"), strings.Count(translated, "%"), "%s %s", lang, id)
+			}
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	cases := map[string]string{
+		"":                           English,
+		"*":                          English,
+		"fr-FR":                      English,
+		"en-US,en;q=0.9":             English,
+		"zh-CN":                      SimplifiedChinese,
+		"zh":                         SimplifiedChinese,
+		"zh-Hans-CN;q=0.8, en;q=0.5": SimplifiedChinese,
+		"en;q=0.5, zh-CN;q=0.9":      SimplifiedChinese,
+		"not a language tag!!":       English,
+	}
+	for header, want := range cases {
+		assert.Equal(t, want, Negotiate(header), header)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	assert.Equal(t, "User created successfully", Translate(English, "user.created"))
+	assert.Equal(t, "用户创建成功", Translate(SimplifiedChinese, "user.created"))
+	assert.Equal(t, "must be at least 3 characters", Translate(English, "validation.min.string", "3"))
+	assert.Equal(t, "长度不能少于 3 个字符", Translate(SimplifiedChinese, "validation.min.string", "3"))
+
+	// Unknown languages fall back to the default; unknown IDs are plain text
+	assert.Equal(t, "User created successfully", Translate("fr", "user.created"))
+	assert.Equal(t, "Failed: 100%", Translate(SimplifiedChinese, "Failed: 100%"))
+	assert.True(t, Has("user.created"))
+	assert.False(t, Has("Failed: 100%"))
+}
+
+func TestFromContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	assert.Equal(t, Default, FromContext(c))
+
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	c.Request.Header.Set("Accept-Language", "zh-CN,zh;q=0.9")
+	assert.Equal(t, SimplifiedChinese, FromContext(c))
+	assert.Equal(t, "用户不存在", T(c, "user.not_found"))
+
+	// A language stored by middleware wins over the header
+	c.Set(ContextKey, English)
+	assert.Equal(t, "User not found", T(c, "user.not_found"))
+}
@@ -0,0 +1,70 @@
+package log
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// requestFields 请求范围的日志字段，处理器添加的字段同样会出现在访问日志中
+type requestFields struct {
+	mu     sync.Mutex
+	fields logrus.Fields
+}
+
+type contextKey struct{}
+
+// NewContext 返回携带请求范围日志字段的context，由LoggerMiddleware在请求开始时调用
+func NewContext(ctx context.Context, fields logrus.Fields) context.Context {
+	rf := &requestFields{fields: make(logrus.Fields, len(fields))}
+	for k, v := range fields {
+		rf.fields[k] = v
+	}
+	return context.WithValue(ctx, contextKey{}, rf)
+}
+
+// FromContext 返回带有请求范围字段的日志Entry，ctx可以是*gin.Context
+// ctx中没有请求字段时返回Logger的Entry
+func FromContext(ctx context.Context) *logrus.Entry {
+	ctx = requestContext(ctx)
+	return Logger.WithContext(ctx).WithFields(Fields(ctx))
+}
+
+// AddFields 向请求范围的日志添加字段，之后的FromContext和本次请求的访问日志都会包含这些字段
+// ctx中没有请求字段时不做任何事
+func AddFields(ctx context.Context, fields logrus.Fields) {
+	rf, ok := requestContext(ctx).Value(contextKey{}).(*requestFields)
+	if !ok {
+		return
+	}
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	for k, v := range fields {
+		rf.fields[k] = v
+	}
+}
+
+// Fields 返回请求范围日志字段的副本
+func Fields(ctx context.Context) logrus.Fields {
+	rf, ok := requestContext(ctx).Value(contextKey{}).(*requestFields)
+	if !ok {
+		return logrus.Fields{}
+	}
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	fields := make(logrus.Fields, len(rf.fields))
+	for k, v := range rf.fields {
+		fields[k] = v
+	}
+	return fields
+}
+
+// requestContext gin.Context只在启用ContextWithFallback时才查找请求的context，这里直接使用请求的context
+func requestContext(ctx context.Context) context.Context {
+	if c, ok := ctx.(*gin.Context); ok && c.Request != nil {
+		return c.Request.Context()
+	}
+	return ctx
+}
@@ -0,0 +1,38 @@
+package log
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestContextFields(t *testing.T) {
+	ctx := NewContext(context.Background(), logrus.Fields{"request_id": "abc"})
+	AddFields(ctx, logrus.Fields{"user_id": "42"})
+
+	entry := FromContext(ctx)
+	assert.Equal(t, logrus.Fields{"request_id": "abc", "user_id": "42"}, entry.Data)
+	assert.Equal(t, ctx, entry.Context)
+
+	// Fields returns a copy
+	Fields(ctx)["user_id"] = "changed"
+	assert.Equal(t, "42", Fields(ctx)["user_id"])
+
+	// Without request fields FromContext falls back to Logger and AddFields is a no-op
+	AddFields(context.Background(), logrus.Fields{"ignored": true})
+	assert.Empty(t, FromContext(context.Background()).Data)
+}
+
+func TestFromGinContext(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	c.Request = c.Request.WithContext(NewContext(c.Request.Context(), logrus.Fields{"path": "/"}))
+
+	AddFields(c, logrus.Fields{"target_id": "7"})
+	assert.Equal(t, logrus.Fields{"path": "/", "target_id": "7"}, FromContext(c).Data)
+}
@@ -0,0 +1,186 @@
+package log
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ComponentField 组件日志中记录组件名的字段
+const ComponentField = "component"
+
+// ErrUnknownComponent 组件未通过Component注册
+var ErrUnknownComponent = errors.New("unknown log component")
+
+// LevelStatus 全局或某个组件当前的日志级别
+type LevelStatus struct {
+	Component string       // 为空表示全局
+	Level     logrus.Level // 当前生效的级别
+	Override  bool         // 是否为运行时通过SetLevel设置的级别
+	ExpiresAt time.Time    // 自动恢复配置级别的时间，零值表示不会自动恢复
+}
+
+// levelOverride 运行时设置的日志级别
+type levelOverride struct {
+	level   logrus.Level
+	expires time.Time
+	timer   *time.Timer
+}
+
+var (
+	levelMu    sync.RWMutex
+	baseLevel  = logrus.InfoLevel            // 日志输出配置中最详细的级别
+	components = map[string]struct{}{}       // 已注册的组件
+	overrides  = map[string]*levelOverride{} // 运行时设置的级别，键为组件名，空字符串表示全局
+)
+
+// Component 返回命名组件的日志Entry，其级别可以通过SetLevel单独调整
+func Component(name string) *logrus.Entry {
+	levelMu.Lock()
+	components[name] = struct{}{}
+	levelMu.Unlock()
+	return Logger.WithField(ComponentField, name)
+}
+
+// Levels 返回全局和所有已注册组件的日志级别，全局在前，组件按名称排序
+func Levels() []LevelStatus {
+	levelMu.RLock()
+	defer levelMu.RUnlock()
+
+	names := make([]string, 0, len(components))
+	for name := range components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	statuses := []LevelStatus{statusLocked("")}
+	for _, name := range names {
+		statuses = append(statuses, statusLocked(name))
+	}
+	return statuses
+}
+
+// GetLevel 返回全局（component为空）或某个组件的日志级别
+func GetLevel(component string) (LevelStatus, error) {
+	levelMu.RLock()
+	defer levelMu.RUnlock()
+	if !knownLocked(component) {
+		return LevelStatus{}, ErrUnknownComponent
+	}
+	return statusLocked(component), nil
+}
+
+// SetLevel 在运行时设置全局（component为空）或某个组件的日志级别，覆盖日志输出配置的级别
+// ttl大于0时到期自动恢复，避免调试日志被遗忘
+func SetLevel(component string, level logrus.Level, ttl time.Duration) (LevelStatus, error) {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	if !knownLocked(component) {
+		return LevelStatus{}, ErrUnknownComponent
+	}
+
+	stopLocked(component)
+	override := &levelOverride{level: level}
+	if ttl > 0 {
+		override.expires = time.Now().Add(ttl)
+		override.timer = time.AfterFunc(ttl, func() { expire(component, override) })
+	}
+	overrides[component] = override
+	applyLevelLocked()
+	return statusLocked(component), nil
+}
+
+// ResetLevel 取消运行时设置的级别，恢复为日志输出配置的级别
+func ResetLevel(component string) (LevelStatus, error) {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	if !knownLocked(component) {
+		return LevelStatus{}, ErrUnknownComponent
+	}
+
+	stopLocked(component)
+	applyLevelLocked()
+	return statusLocked(component), nil
+}
+
+// expire 在TTL到期时恢复配置的级别，期间被替换或取消的设置不受影响
+func expire(component string, override *levelOverride) {
+	levelMu.Lock()
+	if overrides[component] != override {
+		levelMu.Unlock()
+		return
+	}
+	delete(overrides, component)
+	applyLevelLocked()
+	levelMu.Unlock()
+
+	Logger.WithField(ComponentField, component).Info("log level override expired")
+}
+
+// setBaseLevel 由Configure在日志输出变化时调用
+func setBaseLevel(level logrus.Level) {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	baseLevel = level
+	applyLevelLocked()
+}
+
+// overrideLevel 返回entry所属组件（或全局）在运行时设置的级别
+func overrideLevel(entry *logrus.Entry) (logrus.Level, bool) {
+	levelMu.RLock()
+	defer levelMu.RUnlock()
+	if component, ok := entry.Data[ComponentField].(string); ok {
+		if o, ok := overrides[component]; ok {
+			return o.level, true
+		}
+	}
+	if o, ok := overrides[""]; ok {
+		return o.level, true
+	}
+	return 0, false
+}
+
+// applyLevelLocked 把Logger的级别设为所有可能输出的最详细级别，具体过滤由dispatcher完成
+func applyLevelLocked() {
+	level := baseLevel
+	if o, ok := overrides[""]; ok {
+		level = o.level
+	}
+	for _, o := range overrides {
+		if o.level > level {
+			level = o.level
+		}
+	}
+	Logger.SetLevel(level)
+}
+
+func statusLocked(component string) LevelStatus {
+	status := LevelStatus{Component: component, Level: baseLevel}
+	if o, ok := overrides[""]; ok {
+		status.Level = o.level
+	}
+	if o, ok := overrides[component]; ok {
+		status.Level, status.Override, status.ExpiresAt = o.level, true, o.expires
+	}
+	return status
+}
+
+func stopLocked(component string) {
+	if o, ok := overrides[component]; ok {
+		if o.timer != nil {
+			o.timer.Stop()
+		}
+		delete(overrides, component)
+	}
+}
+
+func knownLocked(component string) bool {
+	if component == "" {
+		return true
+	}
+	_, ok := components[component]
+	return ok
+}
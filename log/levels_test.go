@@ -0,0 +1,84 @@
+package log
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gin-app/config"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	cfg := config.Default().Log
+	cfg.Output = []config.SinkConfig{{Type: "file", Filename: path, Format: "text"}}
+	assert.Nil(t, Configure(cfg))
+	defer Configure(config.Default().Log)
+
+	db := Component("db")
+	web := Component("web")
+	_, err := SetLevel("cache", logrus.DebugLevel, 0)
+	assert.ErrorIs(t, err, ErrUnknownComponent)
+
+	// A component override raises only that component above the sink level
+	status, err := SetLevel("db", logrus.DebugLevel, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, LevelStatus{Component: "db", Level: logrus.DebugLevel, Override: true}, status)
+	db.Debug("db query")
+	web.Debug("web request")
+	Logger.Debug("global detail")
+
+	// A global override applies to everything without its own override
+	_, err = SetLevel("", logrus.ErrorLevel, 0)
+	assert.Nil(t, err)
+	web.Warn("web warning")
+	db.Debug("db query again")
+
+	levels := Levels()
+	assert.Equal(t, "", levels[0].Component)
+	assert.Equal(t, logrus.ErrorLevel, levels[0].Level)
+	status, _ = GetLevel("web")
+	assert.Equal(t, LevelStatus{Component: "web", Level: logrus.ErrorLevel}, status)
+
+	// Resetting restores the configured levels
+	_, err = ResetLevel("")
+	assert.Nil(t, err)
+	_, err = ResetLevel("db")
+	assert.Nil(t, err)
+	assert.Equal(t, logrus.InfoLevel, Logger.GetLevel())
+	Component("db").Debug("db after reset")
+
+	written := readLog(t, path)
+	assert.Contains(t, written, "db query")
+	assert.Contains(t, written, "db query again")
+	assert.NotContains(t, written, "web request")
+	assert.NotContains(t, written, "global detail")
+	assert.NotContains(t, written, "web warning")
+	assert.NotContains(t, written, "db after reset")
+}
+
+func TestLevelOverrideExpires(t *testing.T) {
+	Component("expiring")
+	status, err := SetLevel("expiring", logrus.TraceLevel, 20*time.Millisecond)
+	assert.Nil(t, err)
+	assert.False(t, status.ExpiresAt.IsZero())
+	assert.Equal(t, logrus.TraceLevel, Logger.GetLevel())
+
+	assert.Eventually(t, func() bool {
+		status, _ := GetLevel("expiring")
+		return !status.Override
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, logrus.InfoLevel, Logger.GetLevel())
+
+	// Replacing an override cancels the earlier timer
+	_, _ = SetLevel("expiring", logrus.DebugLevel, 20*time.Millisecond)
+	_, _ = SetLevel("expiring", logrus.DebugLevel, 0)
+	time.Sleep(50 * time.Millisecond)
+	status, _ = GetLevel("expiring")
+	assert.True(t, status.Override)
+	_, _ = ResetLevel("expiring")
+	assert.Equal(t, logrus.InfoLevel, Logger.GetLevel())
+}
@@ -1,48 +1,108 @@
 package log
 
 import (
-	"os"
+	"io"
+	"sync"
+	"sync/atomic"
 
 	"gin-app/config"
 
 	"github.com/sirupsen/logrus"
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 var Logger = logrus.New()
 
-func Init() {
-	// 设置日志级别
-	level, err := logrus.ParseLevel(config.GlobalConfig.Log.Level)
+// accessLogger 记录HTTP访问日志，为nil时访问日志写入Logger
+var accessLogger atomic.Pointer[logrus.Logger]
+
+// 当前日志输出打开的文件和连接，重新配置时关闭
+var (
+	outputMu sync.Mutex
+	outputs  []io.Closer
+)
+
+// Init 按启动时加载的配置初始化日志
+func Init(cfg config.LogConfig) error {
+	return Configure(cfg)
+}
+
+// Configure 按配置设置日志的各个输出，可在运行时重复调用（配置热更新）
+// 打开任一输出失败时返回错误，当前的日志配置保持不变
+func Configure(cfg config.LogConfig) error {
+	redactor, err := newRedactor(cfg.Redact)
 	if err != nil {
-		level = logrus.InfoLevel
+		return err
 	}
-	Logger.SetLevel(level)
-
-	// 设置日志格式
-	switch config.GlobalConfig.Log.Format {
-	case "json":
-		Logger.SetFormatter(&logrus.JSONFormatter{})
-	default:
-		Logger.SetFormatter(&logrus.TextFormatter{})
+
+	var opened []io.Closer
+	fail := func(err error) error {
+		closeAll(opened)
+		return err
+	}
+
+	sinks := make([]*sink, 0, len(cfg.Output))
+	for _, sinkConfig := range cfg.Sinks() {
+		s, err := newSink(sinkConfig, cfg)
+		if err != nil {
+			return fail(err)
+		}
+		if s.closer != nil {
+			opened = append(opened, s.closer)
+		}
+		sinks = append(sinks, s)
 	}
 
-	if err := os.MkdirAll("logs", os.ModePerm); err != nil {
-		logrus.Fatalf("Could not create log directory: %v", err)
+	// 配置了访问日志文件时，LoggerMiddleware的请求日志单独写入该文件
+	var access *logrus.Logger
+	if cfg.Access.Filename != "" {
+		format := cfg.Access.Format
+		if format == "" {
+			format = cfg.Format
+		}
+		s, err := newSink(config.SinkConfig{Type: "file", Level: "trace", Format: format, Filename: cfg.Access.Filename}, cfg)
+		if err != nil {
+			return fail(err)
+		}
+		opened = append(opened, s.closer)
+
+		access = logrus.New()
+		access.AddHook(redaction)
+		access.SetLevel(logrus.TraceLevel)
+		access.SetFormatter(&dispatcher{sinks: []*sink{s}})
+		access.SetOutput(io.Discard)
 	}
 
-	switch config.GlobalConfig.Log.Output {
-	case "stdout":
-		Logger.Out = os.Stdout
-	case "file":
-		Logger.Out = &lumberjack.Logger{
-			Filename:   "logs/" + config.GlobalConfig.Log.Filename,
-			MaxSize:    config.GlobalConfig.Log.MaxSize,
-			MaxBackups: config.GlobalConfig.Log.MaxBackups,
-			MaxAge:     config.GlobalConfig.Log.MaxAge,
-			Compress:   config.GlobalConfig.Log.Compress,
+	// Logger的级别取所有输出中最详细的级别，各输出再按自己的级别过滤
+	level := logrus.PanicLevel
+	for _, s := range sinks {
+		if s.level > level {
+			level = s.level
 		}
-	default:
-		Logger.Out = os.Stdout
+	}
+	redaction.current.Store(redactor)
+	Logger.SetFormatter(&dispatcher{sinks: sinks, overridable: true})
+	Logger.SetOutput(io.Discard)
+	setBaseLevel(level)
+	accessLogger.Store(access)
+
+	outputMu.Lock()
+	previous := outputs
+	outputs = opened
+	outputMu.Unlock()
+	closeAll(previous)
+	return nil
+}
+
+// AccessLogger 返回HTTP访问日志使用的Logger，未配置 log.access.filename 时为Logger
+func AccessLogger() *logrus.Logger {
+	if access := accessLogger.Load(); access != nil {
+		return access
+	}
+	return Logger
+}
+
+func closeAll(closers []io.Closer) {
+	for _, c := range closers {
+		c.Close()
 	}
 }
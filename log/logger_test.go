@@ -1,15 +1,68 @@
 package log
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"gin-app/config"
+
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestLoggerInit(t *testing.T) {
 	// Assuming the init function sets up the logger
-	Init()
+	Init(config.Default().Log)
 	// Add assertions to verify the logger is initialized correctly
 	assert.Equal(t, logrus.InfoLevel, Logger.GetLevel())
 }
+
+func TestConfigureSinks(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.Default().Log
+	cfg.Output = []config.SinkConfig{
+		{Type: "file", Filename: filepath.Join(dir, "app.log"), Format: "json"},
+		{Type: "file", Filename: filepath.Join(dir, "debug.log"), Format: "text", Level: "debug"},
+	}
+	cfg.Access = config.AccessLogConfig{Filename: filepath.Join(dir, "access.log")}
+	assert.Nil(t, Configure(cfg))
+	defer Configure(config.Default().Log)
+
+	// Logger runs at the most verbose sink level; each sink filters on its own
+	assert.Equal(t, logrus.DebugLevel, Logger.GetLevel())
+	Logger.Debug("debugging")
+	Logger.WithField("user", "alice").Info("hello")
+	AccessLogger().Info("GET /ping")
+
+	app := readLog(t, filepath.Join(dir, "app.log"))
+	assert.Equal(t, 1, strings.Count(app, "\n"))
+	assert.Contains(t, app, `"msg":"hello"`)
+	assert.Contains(t, app, `"user":"alice"`)
+
+	debug := readLog(t, filepath.Join(dir, "debug.log"))
+	assert.Equal(t, 2, strings.Count(debug, "\n"))
+	assert.Contains(t, debug, `msg=debugging`)
+	assert.Contains(t, debug, `msg=hello user=alice`)
+
+	access := readLog(t, filepath.Join(dir, "access.log"))
+	assert.Contains(t, access, `"msg":"GET /ping"`)
+	assert.NotContains(t, app, "GET /ping")
+
+	// Without an access log file requests are logged by Logger
+	assert.Nil(t, Configure(config.Default().Log))
+	assert.Same(t, Logger, AccessLogger())
+}
+
+func TestConfigureRejectsBadOutput(t *testing.T) {
+	cfg := config.Default().Log
+	cfg.Output = []config.SinkConfig{{Type: "stdout"}, {Type: "kafka"}}
+	assert.ErrorContains(t, Configure(cfg), `unknown log output "kafka"`)
+}
+
+func readLog(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	return string(data)
+}
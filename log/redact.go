@@ -0,0 +1,210 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"gin-app/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+// redaction 在所有日志条目写出前脱敏，规则随Configure更新
+// 在导入时注册为Logger的第一个hook，之后添加的hook看到的都是脱敏后的条目
+var redaction = &redactHook{}
+
+func init() {
+	r, err := newRedactor(config.Default().Log.Redact)
+	if err != nil {
+		panic(fmt.Sprintf("log: default redaction rules: %v", err))
+	}
+	redaction.current.Store(r)
+	Logger.AddHook(redaction)
+}
+
+// redactHook 按当前规则替换日志消息和字段中的敏感数据
+type redactHook struct {
+	current atomic.Pointer[redactor]
+}
+
+func (h *redactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *redactHook) Fire(entry *logrus.Entry) error {
+	r := h.current.Load()
+	if r == nil {
+		return nil
+	}
+	entry.Message = r.string(entry.Message)
+	for key, value := range entry.Data {
+		entry.Data[key] = r.field(key, value, 0)
+	}
+	return nil
+}
+
+// redactor 一组编译后的脱敏规则
+type redactor struct {
+	fields   []string
+	patterns []*regexp.Regexp
+	mask     string
+}
+
+// maxRedactDepth 限制嵌套值的递归深度
+const maxRedactDepth = 8
+
+func newRedactor(cfg config.RedactConfig) (*redactor, error) {
+	r := &redactor{mask: cfg.Mask}
+	if r.mask == "" {
+		r.mask = "[REDACTED]"
+	}
+	for _, field := range cfg.Fields {
+		r.fields = append(r.fields, strings.ToLower(field))
+	}
+	for _, pattern := range cfg.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("redaction pattern %q: %w", pattern, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	if len(cfg.QueryParams) > 0 {
+		names := make([]string, len(cfg.QueryParams))
+		for i, name := range cfg.QueryParams {
+			names[i] = regexp.QuoteMeta(name)
+		}
+		r.patterns = append(r.patterns, regexp.MustCompile(`(?i)[?&](?:`+strings.Join(names, "|")+`)=([^&#\s"']*)`))
+	}
+	return r, nil
+}
+
+// sensitive 判断字段名是否包含敏感词
+func (r *redactor) sensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, field := range r.fields {
+		if strings.Contains(key, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// field 脱敏一个命名的值，敏感字段整体替换
+func (r *redactor) field(key string, value interface{}, depth int) interface{} {
+	if r.sensitive(key) {
+		return r.mask
+	}
+	return r.value(value, depth)
+}
+
+// value 脱敏字符串、错误、map、切片和结构体中的敏感数据
+func (r *redactor) value(value interface{}, depth int) interface{} {
+	if depth > maxRedactDepth {
+		return r.mask
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return r.string(v)
+	case error:
+		// 未包含敏感数据的错误保持原样
+		if s := r.string(v.Error()); s != v.Error() {
+			return s
+		}
+		return v
+	case fmt.Stringer:
+		if s := r.string(v.String()); s != v.String() {
+			return s
+		}
+		return v
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return value
+		}
+		redacted := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			redacted[key] = r.field(key, iter.Value().Interface(), depth+1)
+		}
+		return redacted
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return value // []byte
+		}
+		redacted := make([]interface{}, rv.Len())
+		for i := range redacted {
+			redacted[i] = r.value(rv.Index(i).Interface(), depth+1)
+		}
+		return redacted
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return value
+		}
+		if rv.Elem().Kind() != reflect.Struct {
+			return r.value(rv.Elem().Interface(), depth+1)
+		}
+		return r.structValue(value, depth)
+	case reflect.Struct:
+		return r.structValue(value, depth)
+	}
+	return value
+}
+
+// structValue 结构体按其JSON形式脱敏，这样请求体等结构体中的密码字段也会按字段名替换
+func (r *redactor) structValue(value interface{}, depth int) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return r.string(fmt.Sprintf("%+v", value))
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return r.mask
+	}
+	return r.value(decoded, depth+1)
+}
+
+// string 按正则规则替换字符串中的敏感数据
+func (r *redactor) string(s string) string {
+	for _, re := range r.patterns {
+		s = r.replace(re, s)
+	}
+	return s
+}
+
+// replace 有捕获组时只替换捕获组，保留前面的参数名等上下文
+func (r *redactor) replace(re *regexp.Regexp, s string) string {
+	if re.NumSubexp() == 0 {
+		return re.ReplaceAllLiteralString(s, r.mask)
+	}
+
+	matches := re.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		for g := 2; g+1 < len(m); g += 2 {
+			start, end := m[g], m[g+1]
+			if start < last || start < 0 {
+				continue
+			}
+			b.WriteString(s[last:start])
+			b.WriteString(r.mask)
+			last = end
+		}
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
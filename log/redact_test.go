@@ -0,0 +1,87 @@
+package log
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"gin-app/config"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func defaultRedactor(t *testing.T) *redactor {
+	r, err := newRedactor(config.Default().Log.Redact)
+	assert.Nil(t, err)
+	return r
+}
+
+func TestRedactStrings(t *testing.T) {
+	r := defaultRedactor(t)
+
+	for input, want := range map[string]string{
+		"Authorization: Bearer abc.def-123":          "Authorization: Bearer [REDACTED]",
+		"token eyJhbGciOi.eyJzdWIiOi.c2lnbmF0dXJl":   "token [REDACTED]",
+		`login failed: password=hunter2, retrying`:   `login failed: password=[REDACTED], retrying`,
+		`{"refresh_token":"r-1","username":"alice"}`: `{"refresh_token":"[REDACTED]","username":"alice"}`,
+		"contact alice@example.com":                  "contact [REDACTED]",
+		"?page=2&access_token=xyz&sort=name":         "?page=2&access_token=[REDACTED]&sort=name",
+		"/users?api_key=k1":                          "/users?api_key=[REDACTED]",
+		"nothing to hide":                            "nothing to hide",
+	} {
+		assert.Equal(t, want, r.string(input), input)
+	}
+}
+
+func TestRedactFields(t *testing.T) {
+	r := defaultRedactor(t)
+	type signup struct {
+		Username string `json:"username"`
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+
+	assert.Equal(t, "[REDACTED]", r.field("Password", "hunter2", 0))
+	assert.Equal(t, "[REDACTED]", r.field("new_password", "hunter2", 0))
+	assert.Equal(t, "[REDACTED]", r.field("refresh_token", "r-1", 0))
+	assert.Equal(t, "alice", r.field("username", "alice", 0))
+	assert.Equal(t, 42, r.field("status", 42, 0))
+
+	// Structs, maps, headers and slices are redacted by key at any depth
+	assert.Equal(t, map[string]interface{}{"username": "alice", "email": "[REDACTED]", "password": "[REDACTED]"},
+		r.value(&signup{Username: "alice", Email: "a@example.com", Password: "hunter2"}, 0))
+	assert.Equal(t, map[string]interface{}{
+		"Authorization": "[REDACTED]",
+		"Accept":        []interface{}{"application/json"},
+	}, r.value(http.Header{"Authorization": {"Bearer x"}, "Accept": {"application/json"}}, 0))
+	assert.Equal(t, []interface{}{map[string]interface{}{"secret": "[REDACTED]"}},
+		r.value([]map[string]string{{"secret": "s"}}, 0))
+
+	// Errors keep their type unless they contain sensitive data
+	plain := errors.New("user not found")
+	assert.Equal(t, plain, r.value(plain, 0))
+	assert.Equal(t, "bad credentials password=[REDACTED]", r.value(errors.New("bad credentials password=hunter2"), 0))
+}
+
+func TestRedactHookAppliesToEveryEntry(t *testing.T) {
+	r := defaultRedactor(t)
+	entry := logrus.NewEntry(Logger).WithFields(logrus.Fields{
+		"password": "hunter2",
+		"query":    "?token=abc",
+		"user":     "alice",
+	})
+	entry.Message = "signing in with password: hunter2"
+
+	hook := &redactHook{}
+	hook.current.Store(r)
+	assert.Nil(t, hook.Fire(entry))
+	assert.Equal(t, "signing in with password: [REDACTED]", entry.Message)
+	assert.Equal(t, logrus.Fields{"password": "[REDACTED]", "query": "?token=[REDACTED]", "user": "alice"}, entry.Data)
+}
+
+func TestConfigureRejectsBadRedactPattern(t *testing.T) {
+	cfg := config.Default().Log
+	cfg.Redact.Patterns = []string{"("}
+	assert.ErrorContains(t, Configure(cfg), "redaction pattern")
+}
@@ -0,0 +1,121 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gin-app/config"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// sink 一个日志输出，拥有独立的级别和格式
+type sink struct {
+	name      string
+	level     logrus.Level
+	formatter logrus.Formatter
+	write     func(level logrus.Level, p []byte) error
+	closer    io.Closer
+}
+
+// newSink 按输出配置创建日志输出，文件轮转参数取自logCfg
+func newSink(cfg config.SinkConfig, logCfg config.LogConfig) (*sink, error) {
+	level, err := logrus.ParseLevel(cfg.Level)
+	if err != nil {
+		level = logrus.InfoLevel
+	}
+	s := &sink{name: cfg.Type, level: level, formatter: newFormatter(cfg.Format)}
+
+	switch cfg.Type {
+	case "stdout":
+		s.write = writeTo(os.Stdout)
+	case "file":
+		path := logPath(cfg.Filename)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return nil, fmt.Errorf("creating log directory: %w", err)
+		}
+		file := &lumberjack.Logger{
+			Filename:   path,
+			MaxSize:    logCfg.MaxSize,
+			MaxBackups: logCfg.MaxBackups,
+			MaxAge:     logCfg.MaxAge,
+			Compress:   logCfg.Compress,
+		}
+		s.name = "file " + path
+		s.write, s.closer = writeTo(file), file
+	case "syslog":
+		w, err := dialSyslog(cfg)
+		if err != nil {
+			return nil, err
+		}
+		s.write, s.closer = w.write, w
+	default:
+		return nil, fmt.Errorf("unknown log output %q", cfg.Type)
+	}
+	return s, nil
+}
+
+func newFormatter(format string) logrus.Formatter {
+	switch format {
+	case "json":
+		return &logrus.JSONFormatter{}
+	default:
+		return &logrus.TextFormatter{}
+	}
+}
+
+// logPath 相对路径的日志文件位于logs目录下
+func logPath(filename string) string {
+	if filepath.IsAbs(filename) {
+		return filename
+	}
+	return filepath.Join("logs", filename)
+}
+
+func writeTo(w io.Writer) func(logrus.Level, []byte) error {
+	return func(_ logrus.Level, p []byte) error {
+		_, err := w.Write(p)
+		return err
+	}
+}
+
+// dispatcher 作为Logger的Formatter，按各输出自己的级别和格式写出日志条目
+// Logger本身输出到io.Discard；在格式化阶段分发可以保证所有hook都先于输出执行
+type dispatcher struct {
+	sinks       []*sink
+	overridable bool // 运行时设置的级别（SetLevel）是否取代各输出的级别
+}
+
+func (d *dispatcher) Format(entry *logrus.Entry) ([]byte, error) {
+	override, overridden := logrus.Level(0), false
+	if d.overridable {
+		override, overridden = overrideLevel(entry)
+	}
+
+	var errs []error
+	for _, s := range d.sinks {
+		limit := s.level
+		if overridden {
+			limit = override
+		}
+		if entry.Level > limit {
+			continue
+		}
+		// 各格式化器共用entry.Buffer，写出后再复用
+		if entry.Buffer != nil {
+			entry.Buffer.Reset()
+		}
+		p, err := s.formatter.Format(entry)
+		if err == nil {
+			err = s.write(entry.Level, p)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("log output %s: %w", s.name, err))
+		}
+	}
+	return nil, errors.Join(errs...)
+}
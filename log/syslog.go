@@ -0,0 +1,46 @@
+//go:build !windows && !plan9
+
+package log
+
+import (
+	"fmt"
+	"log/syslog"
+
+	"gin-app/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+// syslogWriter 写入syslog；Network和Address为空时连接本机套接字，journald也从该套接字接收
+type syslogWriter struct {
+	w *syslog.Writer
+}
+
+func dialSyslog(cfg config.SinkConfig) (*syslogWriter, error) {
+	w, err := syslog.Dial(cfg.Network, cfg.Address, syslog.LOG_INFO|syslog.LOG_USER, cfg.Tag)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to syslog: %w", err)
+	}
+	return &syslogWriter{w: w}, nil
+}
+
+// write 按日志级别选择syslog的严重程度
+func (s *syslogWriter) write(level logrus.Level, p []byte) error {
+	message := string(p)
+	switch level {
+	case logrus.PanicLevel, logrus.FatalLevel:
+		return s.w.Crit(message)
+	case logrus.ErrorLevel:
+		return s.w.Err(message)
+	case logrus.WarnLevel:
+		return s.w.Warning(message)
+	case logrus.InfoLevel:
+		return s.w.Info(message)
+	default:
+		return s.w.Debug(message)
+	}
+}
+
+func (s *syslogWriter) Close() error {
+	return s.w.Close()
+}
@@ -0,0 +1,23 @@
+//go:build windows || plan9
+
+package log
+
+import (
+	"fmt"
+	"runtime"
+
+	"gin-app/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+// syslogWriter 在不支持syslog的平台上无法创建
+type syslogWriter struct{}
+
+func dialSyslog(config.SinkConfig) (*syslogWriter, error) {
+	return nil, fmt.Errorf("syslog output is not supported on %s", runtime.GOOS)
+}
+
+func (s *syslogWriter) write(logrus.Level, []byte) error { return nil }
+
+func (s *syslogWriter) Close() error { return nil }
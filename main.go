@@ -1,9 +1,71 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+
+	"gin-app/config"
+	"gin-app/log"
 	"gin-app/router"
 )
 
+const usage = "usage: gin-app [flags] [migrate up|down [steps]|status | roles <username> <role,...> | version]"
+
+// commands 是除启动服务器外支持的子命令
+var commands = map[string]func(*config.Config, []string) error{
+	"migrate": runMigrate,
+	"roles":   runRoles,
+}
+
 func main() {
-	router.Serve()
+	// 配置参数：--config、--profile 以及 --port 等覆盖项，可放在子命令前后
+	flags := config.NewFlagSet()
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return
+		}
+		fmt.Fprintf(os.Stderr, "%v\n%s", err, flags.FlagUsages())
+		os.Exit(2)
+	}
+
+	args := flags.Args()
+
+	// 未知子命令（例如拼写错误）直接报错退出，而不是启动服务器
+	if len(args) > 0 && args[0] != "version" && commands[args[0]] == nil {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n%s\n%s", args[0], usage, flags.FlagUsages())
+		os.Exit(2)
+	}
+
+	// gin-app version 不需要配置文件，也不初始化日志
+	if len(args) > 0 && args[0] == "version" {
+		if err := runVersion(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "version: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	cfg, err := config.Load(config.Options{Flags: flags})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config: %v\n", err)
+		os.Exit(1)
+	}
+	if err := log.Init(cfg.Log); err != nil {
+		fmt.Fprintf(os.Stderr, "log: %v\n", err)
+		os.Exit(1)
+	}
+
+	// 子命令：gin-app migrate up|down|status，gin-app roles <username> <role,...>，gin-app version
+	if len(args) > 0 {
+		if err := commands[args[0]](cfg, args[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", args[0], err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	router.Serve(cfg)
 }
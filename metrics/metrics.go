@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry 应用的指标注册表，/metrics 只暴露这里注册的指标
+var Registry = prometheus.NewRegistry()
+
+// HTTP请求的RED指标，route为路由模板（例如 /api/v1/users/:id），未匹配的请求记为 UnmatchedRoute
+var (
+	RequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total number of HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	RequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency in seconds by method, route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	RequestsInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "Number of HTTP requests currently being served by method and route.",
+	}, []string{"method", "route"})
+)
+
+// 业务指标
+var (
+	UsersCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "users_created_total",
+		Help: "Total number of users created.",
+	})
+
+	UsersDeleted = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "users_deleted_total",
+		Help: "Total number of users deleted.",
+	})
+
+	// RateLimited 按限流规则统计被拒绝（429）的请求
+	RateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_rate_limited_total",
+		Help: "Total number of requests rejected by rate limiting by rule.",
+	}, []string{"rule"})
+
+	// Logins 按结果（success、failure）统计登录次数
+	Logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_logins_total",
+		Help: "Total number of login attempts by result.",
+	}, []string{"result"})
+)
+
+// UnmatchedRoute 未匹配任何路由的请求使用的route标签，避免原始路径导致标签无限增长
+const UnmatchedRoute = "unmatched"
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		RequestsTotal,
+		RequestDuration,
+		RequestsInFlight,
+		UsersCreated,
+		UsersDeleted,
+		RateLimited,
+		Logins,
+	)
+	// 登录结果预先初始化为0，便于计算失败率
+	Logins.WithLabelValues("success")
+	Logins.WithLabelValues("failure")
+}
+
+// Handler 返回以Prometheus文本格式输出Registry的HTTP处理器
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	apperrors "gin-app/errors"
+)
+
+// Errors returned by UserRepository implementations. Match them with errors.Is;
+// ErrorHandlerMiddleware maps them to HTTP responses
+var (
+	ErrNotFound          = errors.New("user not found")
+	ErrDuplicateID       = errors.New("user with this ID already exists")
+	ErrDuplicateUsername = errors.New("username already taken")
+	ErrDuplicateEmail    = errors.New("email already in use")
+	// ErrVersionConflict is matched by errors.Is for any VersionConflictError
+	ErrVersionConflict = errors.New("version conflict")
+)
+
+// RegisterErrorMappings registers the HTTP responses for the errors above on mapper.
+// Messages and detail values are i18n message IDs, translated for each request.
+// It is called explicitly at startup; importing models has no side effects
+func RegisterErrorMappings(mapper *apperrors.Mapper) {
+	mapper.Register(
+		apperrors.Mapping{Err: ErrNotFound, StatusCode: http.StatusNotFound, Message: "user.not_found"},
+		apperrors.Mapping{Err: ErrDuplicateID, StatusCode: http.StatusConflict, Message: "user.already_exists",
+			Details: map[string]string{"id": "validation.already_exists"}},
+		apperrors.Mapping{Err: ErrDuplicateUsername, StatusCode: http.StatusConflict, Message: "user.username_taken",
+			Details: map[string]string{"username": "validation.already_taken"}},
+		apperrors.Mapping{Err: ErrDuplicateEmail, StatusCode: http.StatusConflict, Message: "user.email_in_use",
+			Details: map[string]string{"email": "validation.already_in_use"}},
+		apperrors.Mapping{Err: ErrVersionConflict, StatusCode: http.StatusConflict, Message: "user.version_conflict"},
+		apperrors.Mapping{Err: ErrInvalidCursor, StatusCode: http.StatusBadRequest, Message: "error.invalid_cursor",
+			Details: map[string]string{"cursor": "validation.invalid_cursor"}},
+		apperrors.Mapping{Err: ErrInvalidCredentials, StatusCode: http.StatusUnauthorized, Message: "auth.invalid_credentials"},
+	)
+}
+
+// NotFoundError is returned when no user matches a lookup. errors.Is(err, ErrNotFound) holds
+type NotFoundError struct {
+	Field string // lookup key: "id", "username" or "email"
+	Value string
+}
+
+func (e *NotFoundError) Error() string {
+	return ErrNotFound.Error()
+}
+
+// Is makes errors.Is(err, ErrNotFound) true for NotFoundError
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// Details reports the lookup that failed
+func (e *NotFoundError) Details() map[string]string {
+	return map[string]string{e.Field: e.Value}
+}
+
+// notFound returns a NotFoundError for a lookup by field
+func notFound(field, value string) error {
+	return &NotFoundError{Field: field, Value: value}
+}
+
+// VersionConflictError is returned by Update when the user was modified since it was read
+type VersionConflictError struct {
+	ID       string
+	Expected int64 // version the caller read
+	Actual   int64 // version currently stored
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("user %s was modified concurrently: expected version %d, current version %d", e.ID, e.Expected, e.Actual)
+}
+
+// Is makes errors.Is(err, ErrVersionConflict) true for VersionConflictError
+func (e *VersionConflictError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
+
+// Details reports the stale and current versions
+func (e *VersionConflictError) Details() map[string]string {
+	return map[string]string{
+		"id":               e.ID,
+		"expected_version": strconv.FormatInt(e.Expected, 10),
+		"current_version":  strconv.FormatInt(e.Actual, 10),
+	}
+}
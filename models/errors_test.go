@@ -0,0 +1,64 @@
+package models
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apperrors "gin-app/errors"
+)
+
+func TestRepositorySentinelErrors(t *testing.T) {
+	db := openMigratedDB(t, filepath.Join(t.TempDir(), "users.db"))
+	t.Cleanup(func() { db.Close() })
+
+	repos := map[string]UserRepository{
+		"memory": NewInMemoryUserRepository(),
+		"sqlite": NewSQLiteUserRepository(db),
+	}
+	for name, repo := range repos {
+		t.Run(name, func(t *testing.T) {
+			assert.Nil(t, repo.Create(&User{ID: "1", Username: "alice", Email: "alice@example.com"}))
+			assert.Nil(t, repo.Create(&User{ID: "2", Username: "bob", Email: "bob@example.com"}))
+
+			assert.ErrorIs(t, repo.Create(&User{ID: "1", Username: "x", Email: "x@example.com"}), ErrDuplicateID)
+			assert.ErrorIs(t, repo.Create(&User{ID: "3", Username: "alice", Email: "x@example.com"}), ErrDuplicateUsername)
+			assert.ErrorIs(t, repo.Create(&User{ID: "3", Username: "x", Email: "alice@example.com"}), ErrDuplicateEmail)
+
+			_, err := repo.GetByEmail("nobody@example.com")
+			assert.ErrorIs(t, err, ErrNotFound)
+			var notFoundErr *NotFoundError
+			assert.True(t, errors.As(err, &notFoundErr))
+			assert.Equal(t, map[string]string{"email": "nobody@example.com"}, notFoundErr.Details())
+
+			assert.ErrorIs(t, repo.Delete("missing"), ErrNotFound)
+
+			bob, _ := repo.GetByID("2")
+			bob.Email = "alice@example.com"
+			assert.ErrorIs(t, repo.Update(bob), ErrDuplicateEmail)
+		})
+	}
+}
+
+func TestSentinelErrorMapping(t *testing.T) {
+	mapper := apperrors.NewMapper()
+	RegisterErrorMappings(mapper)
+
+	appErr, ok := mapper.FromError(notFound("id", "42"))
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+	assert.Equal(t, map[string]string{"id": "42"}, appErr.Details)
+
+	appErr, ok = mapper.FromError(ErrDuplicateUsername)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusConflict, appErr.StatusCode)
+	assert.Equal(t, map[string]string{"username": "validation.already_taken"}, appErr.Details)
+
+	appErr, ok = mapper.FromError(&VersionConflictError{ID: "1", Expected: 1, Actual: 2})
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusConflict, appErr.StatusCode)
+	assert.Equal(t, "2", appErr.Details["current_version"])
+}
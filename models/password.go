@@ -0,0 +1,251 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrUnsupportedHash is returned when a stored hash was not produced by a known algorithm
+var ErrUnsupportedHash = errors.New("unsupported password hash format")
+
+// ErrInvalidCredentials is returned by Authenticate for an unknown user or wrong password
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// PasswordHasher hashes and verifies passwords with a specific algorithm and parameters
+type PasswordHasher interface {
+	// Hash returns an encoded hash that embeds the algorithm and its parameters
+	Hash(password string) (string, error)
+	// Verify reports whether password matches encoded. It returns ErrUnsupportedHash
+	// when encoded was produced by a different algorithm
+	Verify(encoded, password string) (bool, error)
+	// NeedsRehash reports whether encoded was produced by a different algorithm
+	// or with parameters other than the hasher's current ones
+	NeedsRehash(encoded string) bool
+}
+
+// BcryptHasher implements PasswordHasher using bcrypt
+type BcryptHasher struct {
+	Cost int
+}
+
+// NewBcryptHasher creates a bcrypt hasher; a cost below bcrypt.MinCost selects
+// bcrypt.DefaultCost, as bcrypt itself would, so NeedsRehash compares against
+// the cost hashes are actually produced with
+func NewBcryptHasher(cost int) *BcryptHasher {
+	if cost < bcrypt.MinCost {
+		cost = bcrypt.DefaultCost
+	}
+	return &BcryptHasher{Cost: cost}
+}
+
+// Hash hashes password with the configured cost
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Verify compares password against a bcrypt hash
+func (h *BcryptHasher) Verify(encoded, password string) (bool, error) {
+	if !isBcryptHash(encoded) {
+		return false, ErrUnsupportedHash
+	}
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// NeedsRehash reports whether encoded is not a bcrypt hash with the configured cost
+func (h *BcryptHasher) NeedsRehash(encoded string) bool {
+	if !isBcryptHash(encoded) {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != h.Cost
+}
+
+func isBcryptHash(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+// Argon2idHasher implements PasswordHasher using argon2id. Hashes are encoded in
+// the PHC string format: $argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<key>
+type Argon2idHasher struct {
+	Time       uint32 // number of passes over memory
+	Memory     uint32 // memory in KiB
+	Threads    uint8
+	KeyLength  uint32
+	SaltLength uint32
+}
+
+// NewArgon2idHasher creates an argon2id hasher; zero values select the
+// parameters recommended by RFC 9106 for memory-constrained environments
+func NewArgon2idHasher(time, memory uint32, threads uint8, keyLength, saltLength uint32) *Argon2idHasher {
+	h := &Argon2idHasher{Time: time, Memory: memory, Threads: threads, KeyLength: keyLength, SaltLength: saltLength}
+	if h.Time == 0 {
+		h.Time = 3
+	}
+	if h.Memory == 0 {
+		h.Memory = 64 * 1024
+	}
+	if h.Threads == 0 {
+		h.Threads = 4
+	}
+	if h.KeyLength == 0 {
+		h.KeyLength = 32
+	}
+	if h.SaltLength == 0 {
+		h.SaltLength = 16
+	}
+	return h
+}
+
+// Hash hashes password with a random salt and the configured parameters
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.Time, h.Memory, h.Threads, h.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.Memory, h.Time, h.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify compares password against an argon2id hash using the parameters embedded in it
+func (h *Argon2idHasher) Verify(encoded, password string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+	candidate := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, candidate) == 1, nil
+}
+
+// NeedsRehash reports whether encoded is not an argon2id hash with the configured parameters
+func (h *Argon2idHasher) NeedsRehash(encoded string) bool {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	return params.Time != h.Time || params.Memory != h.Memory || params.Threads != h.Threads ||
+		uint32(len(key)) != h.KeyLength || uint32(len(salt)) != h.SaltLength
+}
+
+func decodeArgon2id(encoded string) (*Argon2idHasher, []byte, []byte, error) {
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, nil, nil, ErrUnsupportedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, nil, nil, ErrUnsupportedHash
+	}
+
+	params := &Argon2idHasher{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads); err != nil {
+		return nil, nil, nil, ErrUnsupportedHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, nil, nil, ErrUnsupportedHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return nil, nil, nil, ErrUnsupportedHash
+	}
+	return params, salt, key, nil
+}
+
+var (
+	passwordHasher      PasswordHasher = NewBcryptHasher(bcrypt.DefaultCost)
+	passwordHasherMutex sync.RWMutex
+
+	// knownHashers verify hashes produced by an algorithm other than the current
+	// one, so switching algorithms does not lock out existing users. Verification
+	// reads parameters from the hash itself, so default parameters suffice here
+	knownHashers = []PasswordHasher{NewBcryptHasher(0), NewArgon2idHasher(0, 0, 0, 0, 0)}
+)
+
+// SetPasswordHasher replaces the hasher used for new passwords and rehash checks
+func SetPasswordHasher(h PasswordHasher) {
+	passwordHasherMutex.Lock()
+	defer passwordHasherMutex.Unlock()
+	passwordHasher = h
+}
+
+func currentPasswordHasher() PasswordHasher {
+	passwordHasherMutex.RLock()
+	defer passwordHasherMutex.RUnlock()
+	return passwordHasher
+}
+
+// SetPassword hashes password with the current hasher and stores the hash on the user
+func (u *User) SetPassword(password string) error {
+	hash, err := currentPasswordHasher().Hash(password)
+	if err != nil {
+		return err
+	}
+	u.Password = hash
+	return nil
+}
+
+// VerifyPassword reports whether password matches the user's stored hash
+func (u *User) VerifyPassword(password string) bool {
+	for _, h := range append([]PasswordHasher{currentPasswordHasher()}, knownHashers...) {
+		ok, err := h.Verify(u.Password, password)
+		if errors.Is(err, ErrUnsupportedHash) {
+			continue
+		}
+		return err == nil && ok
+	}
+	return false
+}
+
+// PasswordNeedsRehash reports whether the stored hash should be upgraded to the current hasher
+func (u *User) PasswordNeedsRehash() bool {
+	return currentPasswordHasher().NeedsRehash(u.Password)
+}
+
+// Authenticate looks up a user by username and verifies password. On success,
+// a hash produced with outdated parameters is transparently upgraded and saved.
+// Unknown users and wrong passwords both yield ErrInvalidCredentials
+func Authenticate(repo UserRepository, username, password string) (*User, error) {
+	user, err := repo.GetByUsername(username)
+	if err != nil {
+		// Hash anyway so response timing does not reveal whether the username exists
+		_, _ = currentPasswordHasher().Hash(password)
+		return nil, ErrInvalidCredentials
+	}
+	if !user.VerifyPassword(password) {
+		return nil, ErrInvalidCredentials
+	}
+
+	if user.PasswordNeedsRehash() {
+		// Work on a copy so a failed save leaves the stored user untouched;
+		// login still succeeds and the rehash is retried next time
+		upgraded := *user
+		if err := upgraded.SetPassword(password); err == nil && repo.Update(&upgraded) == nil {
+			user = &upgraded
+		}
+	}
+	return user, nil
+}
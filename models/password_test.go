@@ -0,0 +1,121 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// useHasher swaps the package hasher for the duration of a test
+func useHasher(t *testing.T, h PasswordHasher) {
+	previous := currentPasswordHasher()
+	SetPasswordHasher(h)
+	t.Cleanup(func() { SetPasswordHasher(previous) })
+}
+
+func fastArgon2id() *Argon2idHasher {
+	return NewArgon2idHasher(1, 1024, 1, 16, 8)
+}
+
+func TestBcryptHasher(t *testing.T) {
+	h := NewBcryptHasher(bcrypt.MinCost)
+
+	hash, err := h.Hash("password123")
+	assert.Nil(t, err)
+	assert.NotEqual(t, "password123", hash)
+
+	ok, err := h.Verify(hash, "password123")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	ok, err = h.Verify(hash, "wrong")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	assert.False(t, h.NeedsRehash(hash))
+	assert.True(t, NewBcryptHasher(bcrypt.MinCost+1).NeedsRehash(hash))
+
+	_, err = h.Verify("$argon2id$v=19$m=1,t=1,p=1$c2FsdA$a2V5", "password123")
+	assert.ErrorIs(t, err, ErrUnsupportedHash)
+
+	// Costs bcrypt would raise to the default do not force a rehash on every login
+	low := NewBcryptHasher(bcrypt.MinCost - 1)
+	hash, err = low.Hash("password123")
+	assert.Nil(t, err)
+	assert.False(t, low.NeedsRehash(hash))
+}
+
+func TestArgon2idHasher(t *testing.T) {
+	h := fastArgon2id()
+
+	hash, err := h.Hash("password123")
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+	ok, err := h.Verify(hash, "password123")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	ok, err = h.Verify(hash, "wrong")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	assert.False(t, h.NeedsRehash(hash))
+	assert.True(t, NewArgon2idHasher(2, 1024, 1, 16, 8).NeedsRehash(hash))
+	assert.True(t, NewBcryptHasher(bcrypt.MinCost).NeedsRehash(hash))
+
+	_, err = h.Verify("plaintext", "plaintext")
+	assert.ErrorIs(t, err, ErrUnsupportedHash)
+}
+
+func TestUserSetAndVerifyPassword(t *testing.T) {
+	useHasher(t, NewBcryptHasher(bcrypt.MinCost))
+
+	user := &User{ID: "1", Username: "testuser"}
+	assert.Nil(t, user.SetPassword("password123"))
+	assert.NotEqual(t, "password123", user.Password)
+	assert.True(t, user.VerifyPassword("password123"))
+	assert.False(t, user.VerifyPassword("wrong"))
+
+	// Hashes from a previous algorithm still verify after switching
+	useHasher(t, fastArgon2id())
+	assert.True(t, user.VerifyPassword("password123"))
+	assert.True(t, user.PasswordNeedsRehash())
+
+	// Plain text is never accepted
+	user.Password = "password123"
+	assert.False(t, user.VerifyPassword("password123"))
+}
+
+func TestAuthenticateRehashesOutdatedPassword(t *testing.T) {
+	useHasher(t, NewBcryptHasher(bcrypt.MinCost))
+
+	repo := NewInMemoryUserRepository()
+	user := &User{ID: "1", Username: "testuser", Email: "test@example.com"}
+	assert.Nil(t, user.SetPassword("password123"))
+	assert.Nil(t, repo.Create(user))
+	originalHash := user.Password
+
+	_, err := Authenticate(repo, "testuser", "wrong")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	_, err = Authenticate(repo, "nobody", "password123")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	// Same parameters: hash is left alone
+	authenticated, err := Authenticate(repo, "testuser", "password123")
+	assert.Nil(t, err)
+	assert.Equal(t, originalHash, authenticated.Password)
+
+	// New parameters: hash is upgraded and persisted
+	useHasher(t, fastArgon2id())
+	authenticated, err = Authenticate(repo, "testuser", "password123")
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(authenticated.Password, "$argon2id$"))
+
+	stored, _ := repo.GetByID("1")
+	assert.True(t, strings.HasPrefix(stored.Password, "$argon2id$"))
+	assert.False(t, stored.PasswordNeedsRehash())
+}
@@ -0,0 +1,196 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Pagination limits for UserQuery
+const (
+	DefaultUserQueryLimit = 20
+	MaxUserQueryLimit     = 100
+)
+
+// Sort fields accepted by UserQuery
+const (
+	SortByCreatedAt = "created_at"
+	SortByUsername  = "username"
+)
+
+// ErrInvalidCursor is returned when a cursor is malformed or was issued for a different sort order
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// UserQuery describes filtering, sorting and pagination for UserRepository.List.
+// Cursor, when set, takes precedence over Offset
+type UserQuery struct {
+	Limit  int
+	Offset int
+	Cursor string
+
+	SortBy   string // created_at (default) or username; ties are broken by ID
+	SortDesc bool
+
+	EmailDomain   string    // exact, case-insensitive match of the part after "@"
+	CreatedAfter  time.Time // inclusive lower bound; zero means unbounded
+	CreatedBefore time.Time // exclusive upper bound; zero means unbounded
+	Search        string    // case-insensitive substring of username or email
+}
+
+// UserPage is one page of UserRepository.List results
+type UserPage struct {
+	Users      []*User
+	Total      int // number of users matching the filters, ignoring pagination
+	Offset     int // position of the first user on this page within the full result
+	NextCursor string
+	PrevCursor string
+}
+
+// userCursor is the decoded form of an opaque pagination cursor. It records the
+// sort key and ID of the user it points at, and the sort order it is valid for
+type userCursor struct {
+	Sort     string `json:"s"`
+	Key      string `json:"k"`
+	ID       string `json:"id"`
+	Backward bool   `json:"b,omitempty"` // page ends before, rather than starts after, this user
+}
+
+// normalize validates the query and applies defaults
+func (q *UserQuery) normalize() error {
+	if q.Limit <= 0 {
+		q.Limit = DefaultUserQueryLimit
+	}
+	if q.Limit > MaxUserQueryLimit {
+		q.Limit = MaxUserQueryLimit
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+	switch q.SortBy {
+	case "":
+		q.SortBy = SortByCreatedAt
+	case SortByCreatedAt, SortByUsername:
+	default:
+		return fmt.Errorf("unsupported sort field %q", q.SortBy)
+	}
+	q.EmailDomain = strings.ToLower(strings.TrimPrefix(q.EmailDomain, "@"))
+	q.Search = strings.ToLower(q.Search)
+	return nil
+}
+
+// sortSpec identifies the sort order a cursor belongs to
+func (q *UserQuery) sortSpec() string {
+	if q.SortDesc {
+		return "-" + q.SortBy
+	}
+	return q.SortBy
+}
+
+// matches reports whether user passes the query filters
+func (q *UserQuery) matches(user *User) bool {
+	if q.EmailDomain != "" {
+		_, domain, _ := strings.Cut(strings.ToLower(user.Email), "@")
+		if domain != q.EmailDomain {
+			return false
+		}
+	}
+	if !q.CreatedAfter.IsZero() && user.CreatedAt.Before(q.CreatedAfter) {
+		return false
+	}
+	if !q.CreatedBefore.IsZero() && !user.CreatedAt.Before(q.CreatedBefore) {
+		return false
+	}
+	if q.Search != "" &&
+		!strings.Contains(strings.ToLower(user.Username), q.Search) &&
+		!strings.Contains(strings.ToLower(user.Email), q.Search) {
+		return false
+	}
+	return true
+}
+
+// sortKey returns the value user is ordered by. Timestamps use the same
+// fixed-width encoding as the SQLite backend so both order identically
+func (q *UserQuery) sortKey(user *User) string {
+	if q.SortBy == SortByUsername {
+		return user.Username
+	}
+	return formatTime(user.CreatedAt)
+}
+
+// compare orders (key, id) pairs according to the query's sort direction
+func (q *UserQuery) compare(keyA, idA, keyB, idB string) int {
+	c := strings.Compare(keyA, keyB)
+	if c == 0 {
+		c = strings.Compare(idA, idB)
+	}
+	if q.SortDesc {
+		return -c
+	}
+	return c
+}
+
+func (q *UserQuery) less(a, b *User) bool {
+	return q.compare(q.sortKey(a), a.ID, q.sortKey(b), b.ID) < 0
+}
+
+func (q *UserQuery) encodeCursor(user *User, backward bool) string {
+	raw, _ := json.Marshal(userCursor{Sort: q.sortSpec(), Key: q.sortKey(user), ID: user.ID, Backward: backward})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func (q *UserQuery) decodeCursor() (*userCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c userCursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID == "" || c.Sort != q.sortSpec() {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// window resolves the query's offset or cursor into the [start, end) range of
+// the sorted result set. countBefore reports how many matching users sort
+// before the cursor position (inclusive of the cursor user itself if asked)
+func (q *UserQuery) window(total int, countBefore func(c *userCursor, inclusive bool) (int, error)) (int, int, error) {
+	if q.Cursor == "" {
+		start := min(q.Offset, total)
+		return start, min(start+q.Limit, total), nil
+	}
+
+	c, err := q.decodeCursor()
+	if err != nil {
+		return 0, 0, err
+	}
+	if c.Backward {
+		end, err := countBefore(c, false)
+		if err != nil {
+			return 0, 0, err
+		}
+		return max(0, end-q.Limit), end, nil
+	}
+	start, err := countBefore(c, true)
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, min(start+q.Limit, total), nil
+}
+
+// page assembles a UserPage for users occupying [start, start+len(users)) of total
+func (q *UserQuery) page(users []*User, start, total int) *UserPage {
+	page := &UserPage{Users: users, Total: total, Offset: start}
+	if len(users) == 0 {
+		return page
+	}
+	if start+len(users) < total {
+		page.NextCursor = q.encodeCursor(users[len(users)-1], false)
+	}
+	if start > 0 {
+		page.PrevCursor = q.encodeCursor(users[0], true)
+	}
+	return page
+}
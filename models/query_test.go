@@ -0,0 +1,141 @@
+package models
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// listTestRepos returns every UserRepository implementation seeded with the same users
+func listTestRepos(t *testing.T) map[string]UserRepository {
+	db := openMigratedDB(t, filepath.Join(t.TempDir(), "users.db"))
+	t.Cleanup(func() { db.Close() })
+
+	repos := map[string]UserRepository{
+		"memory": NewInMemoryUserRepository(),
+		"sqlite": NewSQLiteUserRepository(db),
+	}
+	for _, repo := range repos {
+		// Created in order user0..user4; odd users are on example.org
+		for i := 0; i < 5; i++ {
+			domain := "example.com"
+			if i%2 == 1 {
+				domain = "Example.ORG"
+			}
+			user := &User{
+				ID:       fmt.Sprintf("id%d", i),
+				Username: fmt.Sprintf("user%d", 4-i), // reverse of creation order
+				Email:    fmt.Sprintf("person%d@%s", i, domain),
+			}
+			assert.Nil(t, repo.Create(user))
+			time.Sleep(time.Millisecond)
+		}
+	}
+	return repos
+}
+
+func ids(users []*User) []string {
+	result := make([]string, 0, len(users))
+	for _, u := range users {
+		result = append(result, u.ID)
+	}
+	return result
+}
+
+func TestListSortingAndOffset(t *testing.T) {
+	for name, repo := range listTestRepos(t) {
+		t.Run(name, func(t *testing.T) {
+			page, err := repo.List(UserQuery{})
+			assert.Nil(t, err)
+			assert.Equal(t, 5, page.Total)
+			assert.Equal(t, []string{"id0", "id1", "id2", "id3", "id4"}, ids(page.Users))
+
+			page, err = repo.List(UserQuery{SortBy: SortByUsername})
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"id4", "id3", "id2", "id1", "id0"}, ids(page.Users))
+
+			page, err = repo.List(UserQuery{SortDesc: true, Limit: 2, Offset: 1})
+			assert.Nil(t, err)
+			assert.Equal(t, 5, page.Total)
+			assert.Equal(t, 1, page.Offset)
+			assert.Equal(t, []string{"id3", "id2"}, ids(page.Users))
+			assert.NotEmpty(t, page.NextCursor)
+			assert.NotEmpty(t, page.PrevCursor)
+
+			page, err = repo.List(UserQuery{Offset: 10})
+			assert.Nil(t, err)
+			assert.Len(t, page.Users, 0)
+
+			_, err = repo.List(UserQuery{SortBy: "password"})
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestListFilters(t *testing.T) {
+	for name, repo := range listTestRepos(t) {
+		t.Run(name, func(t *testing.T) {
+			page, err := repo.List(UserQuery{EmailDomain: "example.org"})
+			assert.Nil(t, err)
+			assert.Equal(t, 2, page.Total)
+			assert.Equal(t, []string{"id1", "id3"}, ids(page.Users))
+
+			page, err = repo.List(UserQuery{Search: "USER3"})
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"id1"}, ids(page.Users))
+
+			page, err = repo.List(UserQuery{Search: "person%"})
+			assert.Nil(t, err)
+			assert.Equal(t, 0, page.Total)
+
+			all, _ := repo.List(UserQuery{})
+			page, err = repo.List(UserQuery{
+				CreatedAfter:  all.Users[1].CreatedAt,
+				CreatedBefore: all.Users[3].CreatedAt,
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"id1", "id2"}, ids(page.Users))
+		})
+	}
+}
+
+func TestListCursorPagination(t *testing.T) {
+	for name, repo := range listTestRepos(t) {
+		t.Run(name, func(t *testing.T) {
+			query := UserQuery{SortBy: SortByUsername, Limit: 2}
+
+			first, err := repo.List(query)
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"id4", "id3"}, ids(first.Users))
+			assert.Empty(t, first.PrevCursor)
+
+			query.Cursor = first.NextCursor
+			second, err := repo.List(query)
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"id2", "id1"}, ids(second.Users))
+			assert.Equal(t, 2, second.Offset)
+
+			query.Cursor = second.NextCursor
+			third, err := repo.List(query)
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"id0"}, ids(third.Users))
+			assert.Empty(t, third.NextCursor)
+
+			// Walking backwards returns the previous page
+			query.Cursor = third.PrevCursor
+			back, err := repo.List(query)
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"id2", "id1"}, ids(back.Users))
+
+			// Cursors are tied to the sort order they were issued for
+			_, err = repo.List(UserQuery{SortBy: SortByCreatedAt, Cursor: first.NextCursor})
+			assert.ErrorIs(t, err, ErrInvalidCursor)
+
+			_, err = repo.List(UserQuery{Cursor: "garbage"})
+			assert.ErrorIs(t, err, ErrInvalidCursor)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package models
+
+// Permission is a named capability checked by the authorization policies
+type Permission string
+
+// Permissions for user management
+const (
+	PermissionUsersRead  Permission = "users:read"
+	PermissionUsersWrite Permission = "users:write"
+	PermissionUsersAdmin Permission = "users:admin"
+)
+
+// PermissionSystemAdmin allows operating the running instance through the admin API
+const PermissionSystemAdmin Permission = "system:admin"
+
+// Built-in roles
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// rolePermissions maps each role to the permissions it grants. Plain users get
+// no permissions: the self-or policies let them act on their own record only
+var rolePermissions = map[string][]Permission{
+	RoleUser: {},
+	RoleAdmin: {
+		PermissionUsersRead,
+		PermissionUsersWrite,
+		PermissionUsersAdmin,
+		PermissionSystemAdmin,
+	},
+}
+
+// IsValidRole reports whether role is a known role
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// RolesHavePermission reports whether any of roles grants permission
+func RolesHavePermission(roles []string, permission Permission) bool {
+	for _, role := range roles {
+		for _, p := range rolePermissions[role] {
+			if p == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasPermission reports whether the user's roles grant permission
+func (u *User) HasPermission(permission Permission) bool {
+	return RolesHavePermission(u.Roles, permission)
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserHasPermission(t *testing.T) {
+	user := &User{Roles: []string{RoleUser}}
+	assert.False(t, user.HasPermission(PermissionUsersRead))
+	assert.False(t, user.HasPermission(PermissionUsersWrite))
+
+	admin := &User{Roles: []string{RoleUser, RoleAdmin}}
+	assert.True(t, admin.HasPermission(PermissionUsersAdmin))
+	assert.True(t, admin.HasPermission(PermissionSystemAdmin))
+	assert.False(t, user.HasPermission(PermissionSystemAdmin))
+
+	assert.False(t, (&User{}).HasPermission(PermissionUsersRead))
+	assert.False(t, (&User{Roles: []string{"superuser"}}).HasPermission(PermissionUsersRead))
+}
+
+func TestIsValidRole(t *testing.T) {
+	assert.True(t, IsValidRole(RoleUser))
+	assert.True(t, IsValidRole(RoleAdmin))
+	assert.False(t, IsValidRole("root"))
+}
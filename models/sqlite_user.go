@@ -0,0 +1,296 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const userColumns = "id, username, email, password, roles, version, created_at, updated_at"
+
+// SQLiteUserRepository implements the UserRepository interface with SQLite storage
+type SQLiteUserRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteUserRepository creates a repository backed by db. The users schema
+// is managed by the database package migrations and must be applied first
+func NewSQLiteUserRepository(db *sql.DB) *SQLiteUserRepository {
+	return &SQLiteUserRepository{db: db}
+}
+
+// Ping checks that the database is reachable, for use as a readiness check
+func (r *SQLiteUserRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// Create adds a new user to the repository
+func (r *SQLiteUserRepository) Create(user *User) error {
+	// Set timestamps and initial version
+	now := time.Now()
+
+	_, err := r.db.Exec(
+		"INSERT INTO users ("+userColumns+") VALUES (?, ?, ?, ?, ?, 1, ?, ?)",
+		user.ID, user.Username, user.Email, user.Password, formatRoles(user.Roles),
+		formatTime(now), formatTime(now),
+	)
+	if err != nil {
+		return translateSQLiteError(err)
+	}
+
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	user.Version = 1
+	return nil
+}
+
+// GetByID retrieves a user by ID
+func (r *SQLiteUserRepository) GetByID(id string) (*User, error) {
+	return r.getOne("id", id)
+}
+
+// GetByUsername retrieves a user by username
+func (r *SQLiteUserRepository) GetByUsername(username string) (*User, error) {
+	return r.getOne("username", username)
+}
+
+// GetByEmail retrieves a user by email
+func (r *SQLiteUserRepository) GetByEmail(email string) (*User, error) {
+	return r.getOne("email", email)
+}
+
+// GetAll retrieves all users
+func (r *SQLiteUserRepository) GetAll() ([]*User, error) {
+	rows, err := r.db.Query("SELECT " + userColumns + " FROM users")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]*User, 0)
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// List retrieves a filtered, sorted page of users
+func (r *SQLiteUserRepository) List(query UserQuery) (*UserPage, error) {
+	if err := query.normalize(); err != nil {
+		return nil, err
+	}
+
+	where, args := sqliteUserFilters(&query)
+	column := query.SortBy // validated by normalize, safe to interpolate
+	direction, before := "ASC", "<"
+	if query.SortDesc {
+		direction, before = "DESC", ">"
+	}
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM users"+where, args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	start, end, err := query.window(total, func(c *userCursor, inclusive bool) (int, error) {
+		idOp := before
+		if inclusive {
+			idOp += "="
+		}
+		cond := fmt.Sprintf("(%s %s ? OR (%s = ? AND id %s ?))", column, before, column, idOp)
+		var count int
+		err := r.db.QueryRow(
+			"SELECT COUNT(*) FROM users"+appendCondition(where, cond),
+			append(args, c.Key, c.Key, c.ID)...,
+		).Scan(&count)
+		return count, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(
+		fmt.Sprintf("SELECT %s FROM users%s ORDER BY %s %s, id %s LIMIT ? OFFSET ?", userColumns, where, column, direction, direction),
+		append(args, end-start, start)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]*User, 0, end-start)
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return query.page(users, start, total), nil
+}
+
+// sqliteUserFilters builds the WHERE clause for the query filters
+func sqliteUserFilters(query *UserQuery) (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	if query.EmailDomain != "" {
+		conds = append(conds, "LOWER(email) LIKE ? ESCAPE '\\'")
+		args = append(args, "%@"+escapeLike(query.EmailDomain))
+	}
+	if !query.CreatedAfter.IsZero() {
+		conds = append(conds, "created_at >= ?")
+		args = append(args, formatTime(query.CreatedAfter))
+	}
+	if !query.CreatedBefore.IsZero() {
+		conds = append(conds, "created_at < ?")
+		args = append(args, formatTime(query.CreatedBefore))
+	}
+	if query.Search != "" {
+		conds = append(conds, "(LOWER(username) LIKE ? ESCAPE '\\' OR LOWER(email) LIKE ? ESCAPE '\\')")
+		pattern := "%" + escapeLike(query.Search) + "%"
+		args = append(args, pattern, pattern)
+	}
+
+	if len(conds) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+func appendCondition(where, cond string) string {
+	if where == "" {
+		return " WHERE " + cond
+	}
+	return where + " AND " + cond
+}
+
+// escapeLike escapes LIKE wildcards so user input matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(s)
+}
+
+// Update updates an existing user
+func (r *SQLiteUserRepository) Update(user *User) error {
+	updatedAt := time.Now()
+
+	result, err := r.db.Exec(
+		"UPDATE users SET username = ?, email = ?, password = ?, roles = ?, updated_at = ?, version = version + 1 WHERE id = ? AND version = ?",
+		user.Username, user.Email, user.Password, formatRoles(user.Roles), formatTime(updatedAt), user.ID, user.Version,
+	)
+	if err != nil {
+		return translateSQLiteError(err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		// Either the user is gone or its version moved on
+		var actual int64
+		err := r.db.QueryRow("SELECT version FROM users WHERE id = ?", user.ID).Scan(&actual)
+		if errors.Is(err, sql.ErrNoRows) {
+			return notFound("id", user.ID)
+		}
+		if err != nil {
+			return err
+		}
+		return &VersionConflictError{ID: user.ID, Expected: user.Version, Actual: actual}
+	}
+
+	// Update timestamp and version
+	user.UpdatedAt = updatedAt
+	user.Version++
+	return nil
+}
+
+// Delete removes a user by ID
+func (r *SQLiteUserRepository) Delete(id string) error {
+	result, err := r.db.Exec("DELETE FROM users WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return notFound("id", id)
+	}
+	return nil
+}
+
+// getOne looks up a single user by a unique column and maps sql.ErrNoRows to a NotFoundError
+func (r *SQLiteUserRepository) getOne(column, value string) (*User, error) {
+	user, err := scanUser(r.db.QueryRow("SELECT "+userColumns+" FROM users WHERE "+column+" = ?", value))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, notFound(column, value)
+	}
+	return user, err
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanUser(row rowScanner) (*User, error) {
+	var user User
+	var roles, createdAt, updatedAt string
+	if err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &roles, &user.Version, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	user.Roles = parseRoles(roles)
+
+	var err error
+	if user.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if user.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// sqliteTimeLayout is a fixed-width RFC3339 layout so stored timestamps stay
+// readable with the sqlite3 CLI and sort lexicographically in chronological order
+const sqliteTimeLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimeLayout)
+}
+
+func parseTime(s string) (time.Time, error) {
+	return time.Parse(sqliteTimeLayout, s)
+}
+
+// Roles are stored as a comma-separated list; role names never contain commas
+func formatRoles(roles []string) string {
+	return strings.Join(roles, ",")
+}
+
+func parseRoles(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, ",")
+}
+
+// translateSQLiteError maps unique index violations to the repository's sentinel errors
+func translateSQLiteError(err error) error {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "UNIQUE constraint failed: users.id"):
+		return ErrDuplicateID
+	case strings.Contains(msg, "UNIQUE constraint failed: users.username"):
+		return ErrDuplicateUsername
+	case strings.Contains(msg, "UNIQUE constraint failed: users.email"):
+		return ErrDuplicateEmail
+	}
+	return err
+}
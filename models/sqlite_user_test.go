@@ -0,0 +1,167 @@
+package models
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"gin-app/database"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// openMigratedDB opens a SQLite database at dsn and applies all migrations
+func openMigratedDB(t *testing.T, dsn string) *sql.DB {
+	db, err := database.OpenSQLite(dsn)
+	assert.Nil(t, err)
+	migrator, err := database.NewMigrator(db)
+	assert.Nil(t, err)
+	_, err = migrator.Up()
+	assert.Nil(t, err)
+	return db
+}
+
+func newTestSQLiteRepo(t *testing.T) *SQLiteUserRepository {
+	db := openMigratedDB(t, filepath.Join(t.TempDir(), "users.db"))
+	t.Cleanup(func() { db.Close() })
+	return NewSQLiteUserRepository(db)
+}
+
+func TestSQLiteCreateAndGetUser(t *testing.T) {
+	repo := newTestSQLiteRepo(t)
+
+	user := &User{ID: "1", Username: "testuser", Email: "test@example.com", Password: "secret", Roles: []string{RoleUser, RoleAdmin}}
+	err := repo.Create(user)
+	assert.Nil(t, err)
+	assert.False(t, user.CreatedAt.IsZero())
+	assert.False(t, user.UpdatedAt.IsZero())
+
+	found, err := repo.GetByID("1")
+	assert.Nil(t, err)
+	assert.Equal(t, "testuser", found.Username)
+	assert.Equal(t, "secret", found.Password)
+	assert.Equal(t, []string{RoleUser, RoleAdmin}, found.Roles)
+	assert.True(t, user.CreatedAt.Equal(found.CreatedAt))
+
+	found, err = repo.GetByUsername("testuser")
+	assert.Nil(t, err)
+	assert.Equal(t, "1", found.ID)
+
+	found, err = repo.GetByEmail("test@example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, "1", found.ID)
+
+	_, err = repo.GetByID("999")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func TestSQLiteCreateUserDuplicates(t *testing.T) {
+	repo := newTestSQLiteRepo(t)
+
+	assert.Nil(t, repo.Create(&User{ID: "1", Username: "user1", Email: "user1@example.com"}))
+
+	err := repo.Create(&User{ID: "1", Username: "user2", Email: "user2@example.com"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+
+	err = repo.Create(&User{ID: "2", Username: "user1", Email: "user2@example.com"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "username already taken")
+
+	err = repo.Create(&User{ID: "2", Username: "user2", Email: "user1@example.com"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "email already in use")
+}
+
+func TestSQLiteUpdateUser(t *testing.T) {
+	repo := newTestSQLiteRepo(t)
+
+	user1 := &User{ID: "1", Username: "user1", Email: "user1@example.com"}
+	user2 := &User{ID: "2", Username: "user2", Email: "user2@example.com"}
+	_ = repo.Create(user1)
+	_ = repo.Create(user2)
+
+	user1.Username = "updated"
+	assert.Nil(t, repo.Update(user1))
+
+	found, _ := repo.GetByID("1")
+	assert.Equal(t, "updated", found.Username)
+	assert.True(t, found.UpdatedAt.After(found.CreatedAt))
+
+	user2.Username = "updated"
+	err := repo.Update(user2)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "username already taken")
+
+	err = repo.Update(&User{ID: "999", Username: "ghost", Email: "ghost@example.com"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func TestSQLiteUpdateUserVersionConflict(t *testing.T) {
+	repo := newTestSQLiteRepo(t)
+
+	user := &User{ID: "1", Username: "user1", Email: "user1@example.com"}
+	_ = repo.Create(user)
+	assert.Equal(t, int64(1), user.Version)
+
+	first, _ := repo.GetByID("1")
+	second, _ := repo.GetByID("1")
+
+	first.Username = "first"
+	assert.Nil(t, repo.Update(first))
+	assert.Equal(t, int64(2), first.Version)
+
+	second.Username = "second"
+	err := repo.Update(second)
+	assert.ErrorIs(t, err, ErrVersionConflict)
+
+	var conflict *VersionConflictError
+	assert.ErrorAs(t, err, &conflict)
+	assert.Equal(t, int64(2), conflict.Actual)
+
+	found, _ := repo.GetByID("1")
+	assert.Equal(t, "first", found.Username)
+	assert.Equal(t, int64(2), found.Version)
+}
+
+func TestSQLiteGetAllAndDelete(t *testing.T) {
+	repo := newTestSQLiteRepo(t)
+
+	users, err := repo.GetAll()
+	assert.Nil(t, err)
+	assert.Len(t, users, 0)
+
+	_ = repo.Create(&User{ID: "1", Username: "user1", Email: "user1@example.com"})
+	_ = repo.Create(&User{ID: "2", Username: "user2", Email: "user2@example.com"})
+
+	users, err = repo.GetAll()
+	assert.Nil(t, err)
+	assert.Len(t, users, 2)
+
+	assert.Nil(t, repo.Delete("1"))
+	_, err = repo.GetByID("1")
+	assert.NotNil(t, err)
+
+	err = repo.Delete("1")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func TestSQLitePersistsAcrossReopen(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "nested", "users.db")
+
+	db := openMigratedDB(t, dsn)
+	repo := NewSQLiteUserRepository(db)
+	assert.Nil(t, repo.Create(&User{ID: "1", Username: "user1", Email: "user1@example.com"}))
+	assert.Nil(t, db.Close())
+
+	db = openMigratedDB(t, dsn)
+	defer db.Close()
+	repo = NewSQLiteUserRepository(db)
+
+	found, err := repo.GetByID("1")
+	assert.Nil(t, err)
+	assert.Equal(t, "user1", found.Username)
+}
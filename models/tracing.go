@@ -0,0 +1,112 @@
+package models
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies the spans of TracedUserRepository. The tracer is looked
+// up on each call so that it follows the provider installed by tracing.Init
+const tracerName = "gin-app/models"
+
+// tracedUserRepository wraps every call to a UserRepository in a span that is
+// a child of the span in ctx
+type tracedUserRepository struct {
+	ctx  context.Context
+	repo UserRepository
+}
+
+// TracedUserRepository returns repo with each call recorded as a span under ctx.
+// Repository methods do not take a context, so handlers wrap their repository
+// per request with the request's context
+func TracedUserRepository(ctx context.Context, repo UserRepository) UserRepository {
+	return &tracedUserRepository{ctx: ctx, repo: repo}
+}
+
+func (r *tracedUserRepository) start(operation string, attrs ...attribute.KeyValue) trace.Span {
+	_, span := otel.Tracer(tracerName).Start(r.ctx, "UserRepository."+operation,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attrs...))
+	return span
+}
+
+// IsExpectedError reports whether err is an expected outcome rather than a
+// failure, such as a lookup that finds no user. Repository and handler spans
+// do not mark expected errors as errors
+func IsExpectedError(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// endSpan records err on span unless it is expected
+func endSpan(span trace.Span, err error) {
+	if err != nil && !IsExpectedError(err) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (r *tracedUserRepository) Create(user *User) (err error) {
+	span := r.start("Create")
+	defer func() { endSpan(span, err) }()
+	err = r.repo.Create(user)
+	span.SetAttributes(attribute.String("user.id", user.ID))
+	return err
+}
+
+func (r *tracedUserRepository) GetByID(id string) (user *User, err error) {
+	span := r.start("GetByID", attribute.String("user.id", id))
+	defer func() { endSpan(span, err) }()
+	return r.repo.GetByID(id)
+}
+
+func (r *tracedUserRepository) GetByUsername(username string) (user *User, err error) {
+	span := r.start("GetByUsername")
+	defer func() { endSpan(span, err) }()
+	return r.repo.GetByUsername(username)
+}
+
+func (r *tracedUserRepository) GetByEmail(email string) (user *User, err error) {
+	span := r.start("GetByEmail")
+	defer func() { endSpan(span, err) }()
+	return r.repo.GetByEmail(email)
+}
+
+func (r *tracedUserRepository) GetAll() (users []*User, err error) {
+	span := r.start("GetAll")
+	defer func() { endSpan(span, err) }()
+	users, err = r.repo.GetAll()
+	span.SetAttributes(attribute.Int("user.count", len(users)))
+	return users, err
+}
+
+func (r *tracedUserRepository) List(query UserQuery) (page *UserPage, err error) {
+	span := r.start("List",
+		attribute.Int("query.limit", query.Limit),
+		attribute.Int("query.offset", query.Offset),
+		attribute.String("query.sort", query.SortBy),
+		attribute.Bool("query.cursor", query.Cursor != ""))
+	defer func() { endSpan(span, err) }()
+	page, err = r.repo.List(query)
+	if page != nil {
+		span.SetAttributes(attribute.Int("user.count", len(page.Users)), attribute.Int("user.total", page.Total))
+	}
+	return page, err
+}
+
+func (r *tracedUserRepository) Update(user *User) (err error) {
+	span := r.start("Update", attribute.String("user.id", user.ID), attribute.Int64("user.version", user.Version))
+	defer func() { endSpan(span, err) }()
+	return r.repo.Update(user)
+}
+
+func (r *tracedUserRepository) Delete(id string) (err error) {
+	span := r.start("Delete", attribute.String("user.id", id))
+	defer func() { endSpan(span, err) }()
+	return r.repo.Delete(id)
+}
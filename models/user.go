@@ -1,7 +1,7 @@
 package models
 
 import (
-	"errors"
+	"sort"
 	"sync"
 	"time"
 )
@@ -12,10 +12,19 @@ type User struct {
 	Username  string    `json:"username"`
 	Email     string    `json:"email"`
 	Password  string    `json:"-"` // Password is not exposed in JSON
+	Roles     []string  `json:"roles"`
+	Version   int64     `json:"version"` // incremented on every update, used for optimistic concurrency
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// clone returns a deep copy of the user
+func (u *User) clone() *User {
+	copied := *u
+	copied.Roles = append([]string(nil), u.Roles...)
+	return &copied
+}
+
 // UserRepository defines the interface for User data operations
 type UserRepository interface {
 	Create(user *User) error
@@ -23,11 +32,15 @@ type UserRepository interface {
 	GetByUsername(username string) (*User, error)
 	GetByEmail(email string) (*User, error)
 	GetAll() ([]*User, error)
+	List(query UserQuery) (*UserPage, error)
+	// Update saves user if its Version matches the stored one, then increments
+	// it. A stale Version yields a *VersionConflictError
 	Update(user *User) error
 	Delete(id string) error
 }
 
-// InMemoryUserRepository implements the UserRepository interface with in-memory storage
+// InMemoryUserRepository implements the UserRepository interface with in-memory storage.
+// It stores and returns copies, so callers cannot modify stored users without Update
 type InMemoryUserRepository struct {
 	users map[string]*User
 	mutex sync.RWMutex
@@ -47,26 +60,27 @@ func (r *InMemoryUserRepository) Create(user *User) error {
 
 	// Check if user with same ID already exists
 	if _, exists := r.users[user.ID]; exists {
-		return errors.New("user with this ID already exists")
+		return ErrDuplicateID
 	}
 
 	// Check if username is already taken
 	for _, u := range r.users {
 		if u.Username == user.Username {
-			return errors.New("username already taken")
+			return ErrDuplicateUsername
 		}
 		if u.Email == user.Email {
-			return errors.New("email already in use")
+			return ErrDuplicateEmail
 		}
 	}
 
-	// Set timestamps
+	// Set timestamps and initial version
 	now := time.Now()
 	user.CreatedAt = now
 	user.UpdatedAt = now
+	user.Version = 1
 
 	// Store user
-	r.users[user.ID] = user
+	r.users[user.ID] = user.clone()
 	return nil
 }
 
@@ -77,9 +91,9 @@ func (r *InMemoryUserRepository) GetByID(id string) (*User, error) {
 
 	user, exists := r.users[id]
 	if !exists {
-		return nil, errors.New("user not found")
+		return nil, notFound("id", id)
 	}
-	return user, nil
+	return user.clone(), nil
 }
 
 // GetByUsername retrieves a user by username
@@ -89,10 +103,10 @@ func (r *InMemoryUserRepository) GetByUsername(username string) (*User, error) {
 
 	for _, user := range r.users {
 		if user.Username == username {
-			return user, nil
+			return user.clone(), nil
 		}
 	}
-	return nil, errors.New("user not found")
+	return nil, notFound("username", username)
 }
 
 // GetByEmail retrieves a user by email
@@ -102,10 +116,10 @@ func (r *InMemoryUserRepository) GetByEmail(email string) (*User, error) {
 
 	for _, user := range r.users {
 		if user.Email == email {
-			return user, nil
+			return user.clone(), nil
 		}
 	}
-	return nil, errors.New("user not found")
+	return nil, notFound("email", email)
 }
 
 // GetAll retrieves all users
@@ -115,18 +129,51 @@ func (r *InMemoryUserRepository) GetAll() ([]*User, error) {
 
 	users := make([]*User, 0, len(r.users))
 	for _, user := range r.users {
-		users = append(users, user)
+		users = append(users, user.clone())
 	}
 	return users, nil
 }
 
+// List retrieves a filtered, sorted page of users
+func (r *InMemoryUserRepository) List(query UserQuery) (*UserPage, error) {
+	if err := query.normalize(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	matched := make([]*User, 0, len(r.users))
+	for _, user := range r.users {
+		if query.matches(user) {
+			matched = append(matched, user.clone())
+		}
+	}
+	r.mutex.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool { return query.less(matched[i], matched[j]) })
+
+	start, end, err := query.window(len(matched), func(c *userCursor, inclusive bool) (int, error) {
+		return sort.Search(len(matched), func(i int) bool {
+			cmp := query.compare(query.sortKey(matched[i]), matched[i].ID, c.Key, c.ID)
+			return cmp > 0 || (cmp == 0 && !inclusive)
+		}), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return query.page(matched[start:end], start, len(matched)), nil
+}
+
 // Update updates an existing user
 func (r *InMemoryUserRepository) Update(user *User) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	if _, exists := r.users[user.ID]; !exists {
-		return errors.New("user not found")
+	stored, exists := r.users[user.ID]
+	if !exists {
+		return notFound("id", user.ID)
+	}
+	if stored.Version != user.Version {
+		return &VersionConflictError{ID: user.ID, Expected: user.Version, Actual: stored.Version}
 	}
 
 	// Check if username or email conflicts with another user
@@ -135,18 +182,19 @@ func (r *InMemoryUserRepository) Update(user *User) error {
 			continue
 		}
 		if u.Username == user.Username {
-			return errors.New("username already taken")
+			return ErrDuplicateUsername
 		}
 		if u.Email == user.Email {
-			return errors.New("email already in use")
+			return ErrDuplicateEmail
 		}
 	}
 
-	// Update timestamp
+	// Update timestamp and version
 	user.UpdatedAt = time.Now()
+	user.Version++
 
 	// Update user
-	r.users[user.ID] = user
+	r.users[user.ID] = user.clone()
 	return nil
 }
 
@@ -156,7 +204,7 @@ func (r *InMemoryUserRepository) Delete(id string) error {
 	defer r.mutex.Unlock()
 
 	if _, exists := r.users[id]; !exists {
-		return errors.New("user not found")
+		return notFound("id", id)
 	}
 
 	delete(r.users, id)
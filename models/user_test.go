@@ -168,6 +168,7 @@ func TestUpdateUser(t *testing.T) {
         ID:        "1",
         Username:  "updateduser",
         Email:     "updated@example.com",
+        Version:   user.Version, // Update requires the version that was read
         CreatedAt: originalTime, // CreatedAt should not change
         UpdatedAt: originalTime, // UpdatedAt will be updated by the repo
     }
@@ -234,3 +235,47 @@ func TestDeleteNonExistentUser(t *testing.T) {
     assert.NotNil(t, err)
     assert.Contains(t, err.Error(), "not found")
 }
+
+func TestUpdateUserVersionConflict(t *testing.T) {
+    repo := NewInMemoryUserRepository()
+
+    user := &User{ID: "1", Username: "testuser", Email: "test@example.com"}
+    _ = repo.Create(user)
+    assert.Equal(t, int64(1), user.Version)
+
+    // Two clients read the same version
+    first, _ := repo.GetByID("1")
+    second, _ := repo.GetByID("1")
+
+    first.Username = "first"
+    assert.Nil(t, repo.Update(first))
+    assert.Equal(t, int64(2), first.Version)
+
+    second.Username = "second"
+    err := repo.Update(second)
+    assert.ErrorIs(t, err, ErrVersionConflict)
+
+    var conflict *VersionConflictError
+    assert.ErrorAs(t, err, &conflict)
+    assert.Equal(t, int64(1), conflict.Expected)
+    assert.Equal(t, int64(2), conflict.Actual)
+
+    found, _ := repo.GetByID("1")
+    assert.Equal(t, "first", found.Username)
+}
+
+func TestRepositoryReturnsCopies(t *testing.T) {
+    repo := NewInMemoryUserRepository()
+
+    user := &User{ID: "1", Username: "testuser", Email: "test@example.com"}
+    _ = repo.Create(user)
+
+    // Modifying returned users does not change stored state
+    user.Username = "changed"
+    found, _ := repo.GetByID("1")
+    found.Email = "changed@example.com"
+
+    stored, _ := repo.GetByID("1")
+    assert.Equal(t, "testuser", stored.Username)
+    assert.Equal(t, "test@example.com", stored.Email)
+}
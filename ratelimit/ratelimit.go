@@ -0,0 +1,124 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Algorithms accepted in Policy.Algorithm
+const (
+	// TokenBucket refills Limit tokens evenly over Window and allows bursts of
+	// up to Limit requests
+	TokenBucket = "token_bucket"
+	// SlidingWindow allows Limit requests in any Window, estimated from the
+	// counts of the current and previous fixed windows
+	SlidingWindow = "sliding_window"
+)
+
+// ErrUnknownAlgorithm is returned for a Policy with an unsupported algorithm
+var ErrUnknownAlgorithm = errors.New("unknown rate limit algorithm")
+
+// Policy limits how many requests a key may make
+type Policy struct {
+	Algorithm string
+	Limit     int           // requests allowed per Window
+	Window    time.Duration // period over which Limit applies
+}
+
+// Validate reports whether the policy can be enforced
+func (p Policy) Validate() error {
+	if p.Algorithm != TokenBucket && p.Algorithm != SlidingWindow {
+		return fmt.Errorf("%w %q", ErrUnknownAlgorithm, p.Algorithm)
+	}
+	if p.Limit <= 0 || p.Window <= 0 {
+		return errors.New("rate limit and window must be positive")
+	}
+	return nil
+}
+
+// Result describes a key's quota after a request has been counted
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int           // requests still allowed right now
+	Reset      time.Duration // until the quota is fully restored
+	RetryAfter time.Duration // until the next request is allowed; zero when Allowed
+}
+
+// Store keeps the per-key state of the limiters. Allow must count the request
+// and decide atomically, so that several instances sharing one store enforce
+// a single limit per key
+type Store interface {
+	Allow(key string, policy Policy) (Result, error)
+}
+
+// tokenBucket applies the token bucket algorithm to state at now
+func tokenBucket(state *entry, policy Policy, now time.Time) Result {
+	limit := float64(policy.Limit)
+	rate := limit / policy.Window.Seconds() // tokens per second
+
+	if state.updated.IsZero() {
+		state.tokens = limit
+	} else {
+		state.tokens = math.Min(limit, state.tokens+now.Sub(state.updated).Seconds()*rate)
+	}
+	state.updated = now
+
+	result := Result{Limit: policy.Limit}
+	if state.tokens >= 1 {
+		state.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - state.tokens) / rate)
+	}
+	result.Remaining = int(state.tokens)
+	result.Reset = seconds((limit - state.tokens) / rate)
+	return result
+}
+
+// slidingWindow applies the sliding window counter algorithm to state at now
+func slidingWindow(state *entry, policy Policy, now time.Time) Result {
+	window := policy.Window
+	start := now.Truncate(window)
+	switch {
+	case state.windowStart.Equal(start):
+	case state.windowStart.Add(window).Equal(start):
+		state.previous, state.current = state.current, 0
+		state.windowStart = start
+	default:
+		state.previous, state.current = 0, 0
+		state.windowStart = start
+	}
+
+	elapsed := now.Sub(start)
+	weight := 1 - float64(elapsed)/float64(window) // share of the previous window still inside the sliding window
+	estimate := func() float64 { return float64(state.previous)*weight + float64(state.current) }
+
+	result := Result{Limit: policy.Limit}
+	limit := float64(policy.Limit)
+	if estimate()+1 <= limit {
+		state.current++
+		result.Allowed = true
+	} else if state.current+1 <= policy.Limit {
+		// The previous window's requests must slide out far enough to make room
+		needed := 1 - (limit-1-float64(state.current))/float64(state.previous)
+		result.RetryAfter = time.Duration(needed*float64(window)) - elapsed
+	} else {
+		// Only the next window has room; the current count becomes its previous one
+		needed := 1 - (limit-1)/float64(state.current)
+		result.RetryAfter = window - elapsed + time.Duration(needed*float64(window))
+	}
+
+	result.Remaining = max(0, int(math.Floor(limit-estimate())))
+	result.Reset = window - elapsed
+	if state.current > 0 {
+		result.Reset += window
+	}
+	return result
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
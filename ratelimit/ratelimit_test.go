@@ -0,0 +1,99 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestStore returns a store whose clock only moves when advance is called
+func newTestStore() (*InMemoryStore, func(time.Duration)) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewInMemoryStore()
+	store.now = func() time.Time { return now }
+	return store, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestTokenBucket(t *testing.T) {
+	store, advance := newTestStore()
+	policy := Policy{Algorithm: TokenBucket, Limit: 3, Window: 3 * time.Second}
+
+	// A full bucket allows a burst of Limit requests
+	for i := 2; i >= 0; i-- {
+		result, err := store.Allow("k", policy)
+		assert.Nil(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, i, result.Remaining)
+	}
+	result, _ := store.Allow("k", policy)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+	assert.Equal(t, 3*time.Second, result.Reset)
+
+	// One token is refilled per Window/Limit
+	advance(time.Second)
+	result, _ = store.Allow("k", policy)
+	assert.True(t, result.Allowed)
+	result, _ = store.Allow("k", policy)
+	assert.False(t, result.Allowed)
+
+	// Other keys have their own bucket
+	result, _ = store.Allow("other", policy)
+	assert.True(t, result.Allowed)
+}
+
+func TestSlidingWindow(t *testing.T) {
+	store, advance := newTestStore()
+	policy := Policy{Algorithm: SlidingWindow, Limit: 4, Window: 10 * time.Second}
+
+	for i := 0; i < 4; i++ {
+		result, _ := store.Allow("k", policy)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 3-i, result.Remaining)
+	}
+	result, _ := store.Allow("k", policy)
+	assert.False(t, result.Allowed)
+	// The 4 requests become the previous window; one slot opens once a quarter of it has slid out
+	assert.Equal(t, 10*time.Second+2500*time.Millisecond, result.RetryAfter)
+
+	// Half-way into the next window the previous 4 requests weigh 2
+	advance(15 * time.Second)
+	for i := 0; i < 2; i++ {
+		result, _ = store.Allow("k", policy)
+		assert.True(t, result.Allowed)
+	}
+	result, _ = store.Allow("k", policy)
+	assert.False(t, result.Allowed)
+	// Estimate 4*0.5+2 = 4; at 75% of the window it is 4*0.25+2 = 3
+	assert.Equal(t, 2500*time.Millisecond, result.RetryAfter)
+
+	advance(2500 * time.Millisecond)
+	result, _ = store.Allow("k", policy)
+	assert.True(t, result.Allowed)
+
+	// After two idle windows the quota is full again
+	advance(30 * time.Second)
+	result, _ = store.Allow("k", policy)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 3, result.Remaining)
+}
+
+func TestInMemoryStorePurgesIdleKeys(t *testing.T) {
+	store, advance := newTestStore()
+	policy := Policy{Algorithm: TokenBucket, Limit: 10, Window: time.Second}
+
+	store.Allow("a", policy)
+	store.Allow("b", policy)
+	advance(2 * purgeInterval)
+	store.Allow("c", policy)
+	assert.Len(t, store.entries, 1)
+}
+
+func TestInvalidPolicy(t *testing.T) {
+	store := NewInMemoryStore()
+	_, err := store.Allow("k", Policy{Algorithm: "leaky_bucket", Limit: 1, Window: time.Second})
+	assert.ErrorIs(t, err, ErrUnknownAlgorithm)
+	_, err = store.Allow("k", Policy{Algorithm: TokenBucket, Window: time.Second})
+	assert.NotNil(t, err)
+}
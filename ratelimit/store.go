@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// entry is the limiter state of one key
+type entry struct {
+	// token bucket
+	tokens  float64
+	updated time.Time
+
+	// sliding window
+	windowStart       time.Time
+	previous, current int
+
+	idleAfter time.Time // the state is back to a full quota after this time
+}
+
+// purgeInterval is how often InMemoryStore drops idle keys
+const purgeInterval = time.Minute
+
+// InMemoryStore implements Store in process memory. Limits are enforced per
+// instance; use a shared Store to limit across instances
+type InMemoryStore struct {
+	entries   map[string]*entry
+	mutex     sync.Mutex
+	now       func() time.Time
+	lastPurge time.Time
+}
+
+// NewInMemoryStore creates a new instance of InMemoryStore
+func NewInMemoryStore() *InMemoryStore {
+	return &InMemoryStore{
+		entries: make(map[string]*entry),
+		now:     time.Now,
+	}
+}
+
+// Allow counts a request for key under policy
+func (s *InMemoryStore) Allow(key string, policy Policy) (Result, error) {
+	if err := policy.Validate(); err != nil {
+		return Result{}, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	s.purgeIdle(now)
+
+	state, ok := s.entries[key]
+	if !ok {
+		state = &entry{}
+		s.entries[key] = state
+	}
+
+	var result Result
+	if policy.Algorithm == TokenBucket {
+		result = tokenBucket(state, policy, now)
+	} else {
+		result = slidingWindow(state, policy, now)
+	}
+	state.idleAfter = now.Add(result.Reset)
+	return result, nil
+}
+
+// purgeIdle drops keys whose quota is full again, at most once per
+// purgeInterval; callers must hold the mutex
+func (s *InMemoryStore) purgeIdle(now time.Time) {
+	if now.Sub(s.lastPurge) < purgeInterval {
+		return
+	}
+	s.lastPurge = now
+	for key, state := range s.entries {
+		if now.After(state.idleAfter) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package requestid
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Header 携带请求ID的HTTP头
+const Header = "X-Request-ID"
+
+type contextKey struct{}
+
+// New 生成新的请求ID（UUID v4）
+func New() string {
+	return uuid.NewString()
+}
+
+// Valid 判断客户端传入的请求ID是否为UUID或ULID，其他格式会被替换为新生成的ID
+func Valid(id string) bool {
+	switch len(id) {
+	case 36:
+		_, err := uuid.Parse(id)
+		return err == nil
+	case 26:
+		return isULID(id)
+	}
+	return false
+}
+
+// isULID ULID为26位Crockford Base32字符，首位不超过7（时间戳为48位）
+func isULID(id string) bool {
+	if id[0] < '0' || id[0] > '7' {
+		return false
+	}
+	for _, r := range strings.ToUpper(id) {
+		if !strings.ContainsRune("0123456789ABCDEFGHJKMNPQRSTVWXYZ", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewContext 返回携带请求ID的context
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext 返回ctx中的请求ID，没有时返回空字符串
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Transport 把请求context中的请求ID写入出站请求的X-Request-ID头，已设置该头的请求保持不变
+type Transport struct {
+	Base http.RoundTripper // 为nil时使用http.DefaultTransport
+}
+
+// RoundTrip 实现http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if id := FromContext(req.Context()); id != "" && req.Header.Get(Header) == "" {
+		// RoundTripper不能修改传入的请求
+		req = req.Clone(req.Context())
+		req.Header.Set(Header, id)
+	}
+	return base.RoundTrip(req)
+}
+
+// NewClient 创建传递请求ID的HTTP客户端，应用内的出站请求都应使用它并通过
+// http.NewRequestWithContext传入请求的context
+func NewClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: &Transport{}}
+}
@@ -0,0 +1,57 @@
+package requestid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValid(t *testing.T) {
+	assert.True(t, Valid(New()))
+	assert.True(t, Valid("6f1c2b1e-8d1f-4b7a-9a52-0c1f7f3f2a10"))
+	assert.True(t, Valid("01ARZ3NDEKTSV4RRFFQ69G5FAV"))
+	assert.True(t, Valid("01arz3ndektsv4rrffq69g5fav"))
+
+	assert.False(t, Valid(""))
+	assert.False(t, Valid("abc"))
+	assert.False(t, Valid("81ARZ3NDEKTSV4RRFFQ69G5FAV"))             // ULID timestamp overflow
+	assert.False(t, Valid("01ARZ3NDEKTSV4RRFFQ69G5FAU"))             // U is not in Crockford Base32
+	assert.False(t, Valid("6f1c2b1e-8d1f-4b7a-9a52-0c1f7f3f2a1z"))   // not hex
+	assert.False(t, Valid("{6f1c2b1e-8d1f-4b7a-9a52-0c1f7f3f2a10}")) // braces
+	assert.False(t, Valid("req-1\r\nX-Injected: 1"))
+}
+
+func TestClientPropagatesRequestID(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get(Header))
+	}))
+	defer server.Close()
+
+	client := NewClient(time.Second)
+	ctx := NewContext(context.Background(), "01ARZ3NDEKTSV4RRFFQ69G5FAV")
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Empty(t, req.Header.Get(Header)) // the caller's request is not modified
+
+	// An explicit header wins; requests without an ID are sent unchanged
+	req, _ = http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	req.Header.Set(Header, "upstream")
+	resp, err = client.Do(req)
+	assert.Nil(t, err)
+	resp.Body.Close()
+
+	req, _ = http.NewRequest("GET", server.URL, nil)
+	resp, err = client.Do(req)
+	assert.Nil(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, []string{"01ARZ3NDEKTSV4RRFFQ69G5FAV", "upstream", ""}, received)
+}
@@ -0,0 +1,165 @@
+package responses
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"gin-app/i18n"
+	"gin-app/requestid"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details
+const ProblemContentType = "application/problem+json"
+
+// ProblemTypeBaseURI prefixes the type URI of problem details. The status text
+// in kebab case is appended, e.g. "/problems/not-found"
+var ProblemTypeBaseURI = "/problems/"
+
+// Problem is an RFC 7807 problem details object
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes a validation failure on a single field
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code,omitempty"` // machine-readable rule, e.g. "required", "min", "email"
+	Message string `json:"message"`
+
+	// MessageID and Args, if set, translate Message to the request's language
+	MessageID string        `json:"-"`
+	Args      []interface{} `json:"-"`
+}
+
+// DetailIDs are error details whose values are i18n message IDs, translated to
+// the request's language when written. Plain map[string]string details may hold
+// client-supplied values and are never translated
+type DetailIDs map[string]string
+
+// FieldErrors are field-level validation failures. In the standard envelope
+// they are rendered as an object keyed by field name
+type FieldErrors []FieldError
+
+// MarshalJSON renders {"email": {"code": "email", "message": "..."}}
+func (f FieldErrors) MarshalJSON() ([]byte, error) {
+	type rule struct {
+		Code    string `json:"code,omitempty"`
+		Message string `json:"message"`
+	}
+	byField := make(map[string]rule, len(f))
+	for _, fe := range f {
+		byField[fe.Field] = rule{Code: fe.Code, Message: fe.Message}
+	}
+	return json.Marshal(byField)
+}
+
+// NewProblem builds problem details for an error response. Field-level details
+// given as FieldErrors or map[string]string become the errors array; other data is omitted
+func NewProblem(c *gin.Context, statusCode int, message string, data interface{}) *Problem {
+	problem := &Problem{
+		Type:      problemType(statusCode),
+		Title:     http.StatusText(statusCode),
+		Status:    statusCode,
+		Detail:    message,
+		RequestID: requestID(c),
+	}
+	if c.Request != nil {
+		problem.Instance = c.Request.URL.Path
+	}
+
+	switch details := data.(type) {
+	case FieldErrors:
+		problem.Errors = details
+	case map[string]string:
+		for field, msg := range details {
+			problem.Errors = append(problem.Errors, FieldError{Field: field, Message: msg})
+		}
+		sort.Slice(problem.Errors, func(i, j int) bool { return problem.Errors[i].Field < problem.Errors[j].Field })
+	}
+	return problem
+}
+
+// WantsProblem reports whether the client prefers problem details over the
+// standard response envelope. Clients opt in by listing application/problem+json
+// in Accept ahead of application/json; */* and a missing Accept keep the envelope
+func WantsProblem(c *gin.Context) bool {
+	if c.Request == nil {
+		return false
+	}
+	return c.NegotiateFormat(gin.MIMEJSON, ProblemContentType) == ProblemContentType
+}
+
+// localize returns a copy of f with messages translated to the request's language
+func (f FieldErrors) localize(c *gin.Context) FieldErrors {
+	localized := make(FieldErrors, len(f))
+	for i, fe := range f {
+		if fe.MessageID != "" {
+			fe.Message = i18n.T(c, fe.MessageID, fe.Args...)
+		}
+		localized[i] = fe
+	}
+	return localized
+}
+
+// localize returns the details with every message ID translated to the request's language
+func (d DetailIDs) localize(c *gin.Context) map[string]string {
+	localized := make(map[string]string, len(d))
+	for field, id := range d {
+		localized[field] = i18n.T(c, id)
+	}
+	return localized
+}
+
+// writeError sends an error response as problem details or the standard envelope,
+// depending on the request's Accept header. message may be an i18n message ID;
+// the values of DetailIDs are translated, other details are written as they are
+func writeError(c *gin.Context, statusCode int, message string, data interface{}) {
+	message = i18n.T(c, message)
+	switch details := data.(type) {
+	case FieldErrors:
+		data = details.localize(c)
+	case DetailIDs:
+		data = details.localize(c)
+	}
+
+	c.Writer.Header().Add("Vary", "Accept")
+	if WantsProblem(c) {
+		c.Header("Content-Type", ProblemContentType)
+		c.JSON(statusCode, NewProblem(c, statusCode, message, data))
+		return
+	}
+
+	c.JSON(statusCode, Response{
+		Code:      statusCode,
+		Message:   message,
+		Data:      data,
+		RequestID: requestID(c),
+	})
+}
+
+func problemType(statusCode int) string {
+	text := http.StatusText(statusCode)
+	if text == "" {
+		return "about:blank"
+	}
+	return ProblemTypeBaseURI + strings.ToLower(strings.ReplaceAll(text, " ", "-"))
+}
+
+// requestID returns the request ID assigned by RequestIDMiddleware. The
+// client's X-Request-ID header is never echoed, since it may have failed validation
+func requestID(c *gin.Context) string {
+	if c.Request == nil {
+		return ""
+	}
+	return requestid.FromContext(c.Request.Context())
+}
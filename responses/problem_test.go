@@ -0,0 +1,75 @@
+package responses
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"gin-app/requestid"
+)
+
+func errorRequest(accept string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/api/v1/users/42", nil)
+	c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), "req-1"))
+	if accept != "" {
+		c.Request.Header.Set("Accept", accept)
+	}
+
+	Error(c, http.StatusBadRequest, "Validation error on field 'email'", map[string]string{"email": "invalid", "age": "too low"})
+	return w
+}
+
+// TestProblemResponse tests that clients asking for problem+json get RFC 7807 problem details
+func TestProblemResponse(t *testing.T) {
+	w := errorRequest("application/problem+json")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Values("Vary"), "Accept")
+
+	var problem Problem
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, "/problems/bad-request", problem.Type)
+	assert.Equal(t, "Bad Request", problem.Title)
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Equal(t, "Validation error on field 'email'", problem.Detail)
+	assert.Equal(t, "/api/v1/users/42", problem.Instance)
+	assert.Equal(t, "req-1", problem.RequestID)
+	assert.Equal(t, []FieldError{{Field: "age", Message: "too low"}, {Field: "email", Message: "invalid"}}, problem.Errors)
+}
+
+// TestProblemNegotiation tests that the standard envelope stays the default
+func TestProblemNegotiation(t *testing.T) {
+	for _, accept := range []string{"", "*/*", "application/json", "application/json, application/problem+json"} {
+		w := errorRequest(accept)
+		assert.Contains(t, w.Header().Get("Content-Type"), "application/json", accept)
+
+		var response map[string]interface{}
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, float64(http.StatusBadRequest), response["code"], accept)
+		assert.Equal(t, "req-1", response["request_id"], accept)
+	}
+}
+
+// TestErrorDoesNotEchoClientRequestID tests that only the validated ID from the context is returned
+func TestErrorDoesNotEchoClientRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/api/v1/users/42", nil)
+	c.Request.Header.Set(requestid.Header, "<script>alert(1)</script>")
+
+	BadRequest(c, "error.bad_request")
+
+	var response map[string]interface{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.NotContains(t, response, "request_id")
+	assert.NotContains(t, w.Body.String(), "script")
+}
@@ -4,20 +4,43 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+
+	"gin-app/i18n"
 )
 
-// Response represents a standardized API response structure
+// Response represents a standardized API response structure.
+// Messages passed to the helpers in this package may be i18n message IDs
+// such as "user.created"; they are translated to the request's language
 type Response struct {
-	Code    int         `json:"code"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
+	Code       int         `json:"code"`
+	Message    string      `json:"message"`
+	Data       interface{} `json:"data,omitempty"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+	RequestID  string      `json:"request_id,omitempty"` // set on error responses
+}
+
+// Pagination describes the position of a paginated list response
+type Pagination struct {
+	Total      int    `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	Links      Links  `json:"links"`
+}
+
+// Links holds navigation links for paginated responses
+type Links struct {
+	Self string `json:"self"`
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
 }
 
 // Success sends a successful response with 200 status code
 func Success(c *gin.Context, message string, data interface{}) {
 	c.JSON(http.StatusOK, Response{
 		Code:    http.StatusOK,
-		Message: message,
+		Message: i18n.T(c, message),
 		Data:    data,
 	})
 }
@@ -26,11 +49,21 @@ func Success(c *gin.Context, message string, data interface{}) {
 func Created(c *gin.Context, message string, data interface{}) {
 	c.JSON(http.StatusCreated, Response{
 		Code:    http.StatusCreated,
-		Message: message,
+		Message: i18n.T(c, message),
 		Data:    data,
 	})
 }
 
+// Paginated sends a successful response with 200 status code and pagination metadata
+func Paginated(c *gin.Context, message string, data interface{}, pagination *Pagination) {
+	c.JSON(http.StatusOK, Response{
+		Code:       http.StatusOK,
+		Message:    i18n.T(c, message),
+		Data:       data,
+		Pagination: pagination,
+	})
+}
+
 // NoContent sends a successful response with 204 status code
 func NoContent(c *gin.Context) {
 	c.Status(http.StatusNoContent)
@@ -43,11 +76,7 @@ func BadRequest(c *gin.Context, message string, data ...interface{}) {
 		responseData = data[0]
 	}
 	
-	c.JSON(http.StatusBadRequest, Response{
-		Code:    http.StatusBadRequest,
-		Message: message,
-		Data:    responseData,
-	})
+	writeError(c, http.StatusBadRequest, message, responseData)
 }
 
 // Unauthorized sends an error response with 401 status code
@@ -57,11 +86,7 @@ func Unauthorized(c *gin.Context, message string, data ...interface{}) {
 		responseData = data[0]
 	}
 	
-	c.JSON(http.StatusUnauthorized, Response{
-		Code:    http.StatusUnauthorized,
-		Message: message,
-		Data:    responseData,
-	})
+	writeError(c, http.StatusUnauthorized, message, responseData)
 }
 
 // Forbidden sends an error response with 403 status code
@@ -71,11 +96,7 @@ func Forbidden(c *gin.Context, message string, data ...interface{}) {
 		responseData = data[0]
 	}
 	
-	c.JSON(http.StatusForbidden, Response{
-		Code:    http.StatusForbidden,
-		Message: message,
-		Data:    responseData,
-	})
+	writeError(c, http.StatusForbidden, message, responseData)
 }
 
 // NotFound sends an error response with 404 status code
@@ -85,11 +106,7 @@ func NotFound(c *gin.Context, message string, data ...interface{}) {
 		responseData = data[0]
 	}
 	
-	c.JSON(http.StatusNotFound, Response{
-		Code:    http.StatusNotFound,
-		Message: message,
-		Data:    responseData,
-	})
+	writeError(c, http.StatusNotFound, message, responseData)
 }
 
 // RequestTimeout sends an error response with 408 status code
@@ -99,11 +116,7 @@ func RequestTimeout(c *gin.Context, message string, data ...interface{}) {
 		responseData = data[0]
 	}
 	
-	c.JSON(http.StatusRequestTimeout, Response{
-		Code:    http.StatusRequestTimeout,
-		Message: message,
-		Data:    responseData,
-	})
+	writeError(c, http.StatusRequestTimeout, message, responseData)
 }
 
 // Conflict sends an error response with 409 status code
@@ -113,11 +126,7 @@ func Conflict(c *gin.Context, message string, data ...interface{}) {
 		responseData = data[0]
 	}
 	
-	c.JSON(http.StatusConflict, Response{
-		Code:    http.StatusConflict,
-		Message: message,
-		Data:    responseData,
-	})
+	writeError(c, http.StatusConflict, message, responseData)
 }
 
 // UnprocessableEntity sends an error response with 422 status code
@@ -127,11 +136,17 @@ func UnprocessableEntity(c *gin.Context, message string, data ...interface{}) {
 		responseData = data[0]
 	}
 	
-	c.JSON(http.StatusUnprocessableEntity, Response{
-		Code:    http.StatusUnprocessableEntity,
-		Message: message,
-		Data:    responseData,
-	})
+	writeError(c, http.StatusUnprocessableEntity, message, responseData)
+}
+
+// TooManyRequests sends an error response with 429 status code
+func TooManyRequests(c *gin.Context, message string, data ...interface{}) {
+	var responseData interface{}
+	if len(data) > 0 {
+		responseData = data[0]
+	}
+	
+	writeError(c, http.StatusTooManyRequests, message, responseData)
 }
 
 // InternalServerError sends an error response with 500 status code
@@ -141,11 +156,7 @@ func InternalServerError(c *gin.Context, message string, data ...interface{}) {
 		responseData = data[0]
 	}
 	
-	c.JSON(http.StatusInternalServerError, Response{
-		Code:    http.StatusInternalServerError,
-		Message: message,
-		Data:    responseData,
-	})
+	writeError(c, http.StatusInternalServerError, message, responseData)
 }
 
 // ServiceUnavailable sends an error response with 503 status code
@@ -155,11 +166,7 @@ func ServiceUnavailable(c *gin.Context, message string, data ...interface{}) {
 		responseData = data[0]
 	}
 	
-	c.JSON(http.StatusServiceUnavailable, Response{
-		Code:    http.StatusServiceUnavailable,
-		Message: message,
-		Data:    responseData,
-	})
+	writeError(c, http.StatusServiceUnavailable, message, responseData)
 }
 
 // Error is a generic function that sends an error response with the specified status code
@@ -169,18 +176,14 @@ func Error(c *gin.Context, statusCode int, message string, data ...interface{})
 		responseData = data[0]
 	}
 	
-	c.JSON(statusCode, Response{
-		Code:    statusCode,
-		Message: message,
-		Data:    responseData,
-	})
+	writeError(c, statusCode, message, responseData)
 }
 
 // WithStatusCode sends a response with a custom status code
 func WithStatusCode(c *gin.Context, statusCode int, message string, data interface{}) {
 	c.JSON(statusCode, Response{
 		Code:    statusCode,
-		Message: message,
+		Message: i18n.T(c, message),
 		Data:    data,
 	})
 }
@@ -191,7 +194,7 @@ func WithStatusCode(c *gin.Context, statusCode int, message string, data interfa
 func ResponseWithData(c *gin.Context, code int, message string, data interface{}) {
 	c.JSON(code, Response{
 		Code:    code,
-		Message: message,
+		Message: i18n.T(c, message),
 		Data:    data,
 	})
 }
@@ -200,14 +203,14 @@ func ResponseWithData(c *gin.Context, code int, message string, data interface{}
 func ResponseWithoutData(c *gin.Context, code int, message string) {
 	c.JSON(code, Response{
 		Code:    code,
-		Message: message,
+		Message: i18n.T(c, message),
 	})
 }
 
 // MethodNotAllowed returns a method not allowed error response (HTTP 405)
 func MethodNotAllowed(c *gin.Context, message string) {
 	if message == "" {
-		message = "Method not allowed"
+		message = "error.method_not_allowed"
 	}
 	ResponseWithoutData(c, http.StatusMethodNotAllowed, message)
 }
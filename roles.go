@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gin-app/config"
+	"gin-app/database"
+	"gin-app/models"
+)
+
+const rolesUsage = "usage: gin-app roles <username> <role>[,<role>...]"
+
+// runRoles 直接在数据库中设置用户角色，用于创建第一个管理员
+func runRoles(cfg *config.Config, args []string) error {
+	if len(args) != 2 {
+		return errors.New(rolesUsage)
+	}
+	username, roles := args[0], strings.Split(args[1], ",")
+	for _, role := range roles {
+		if !models.IsValidRole(role) {
+			return fmt.Errorf("unknown role %q", role)
+		}
+	}
+
+	dbConfig := cfg.Database
+	if dbConfig.Driver != "sqlite" {
+		return fmt.Errorf("roles require database.driver \"sqlite\", got %q", dbConfig.Driver)
+	}
+
+	db, err := database.OpenSQLite(dbConfig.DSN)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	repo := models.NewSQLiteUserRepository(db)
+	user, err := repo.GetByUsername(username)
+	if err != nil {
+		return fmt.Errorf("user %q: %w", username, err)
+	}
+	user.Roles = roles
+	if err := repo.Update(user); err != nil {
+		return err
+	}
+
+	fmt.Printf("user %s now has roles: %s\n", username, strings.Join(roles, ","))
+	return nil
+}
@@ -0,0 +1,91 @@
+package router
+
+import (
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gin-app/config"
+	"gin-app/handler"
+	"gin-app/log"
+	"gin-app/ratelimit"
+
+	"github.com/sirupsen/logrus"
+)
+
+// requestTimeout 是当前的请求超时时间，配置热更新时替换
+var requestTimeout atomic.Int64
+
+// currentRequestTimeout 供DynamicTimeoutMiddleware按请求读取
+func currentRequestTimeout() time.Duration {
+	return time.Duration(requestTimeout.Load())
+}
+
+// applyRuntimeConfig 应用可在运行时修改的跨域来源、请求超时和限流规则
+func applyRuntimeConfig(cfg config.Config) {
+	if len(cfg.Server.CORSOrigins) > 0 {
+		handler.SetCORSOrigins(cfg.Server.CORSOrigins)
+	}
+	handler.SetRateLimitRules(rateLimitRules(cfg.RateLimit))
+
+	timeout := cfg.Server.RequestTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	requestTimeout.Store(int64(timeout))
+}
+
+// rateLimitRules 把限流配置转换为中间件规则，未启用时返回空规则
+func rateLimitRules(cfg config.RateLimitConfig) []handler.RateLimitRule {
+	if !cfg.Enabled {
+		return nil
+	}
+	rules := make([]handler.RateLimitRule, 0, len(cfg.Rules))
+	for _, rule := range cfg.Rules {
+		rules = append(rules, handler.RateLimitRule{
+			Name:   rule.Name,
+			Method: rule.Method,
+			Route:  rule.Route,
+			Key:    strings.ToLower(rule.Key),
+			Policy: ratelimit.Policy{
+				Algorithm: strings.ToLower(rule.Algorithm),
+				Limit:     rule.Limit,
+				Window:    rule.Window,
+			},
+		})
+	}
+	return rules
+}
+
+// watchConfig 监听配置文件，变更时重新应用运行时配置
+// 端口、存储、密码、认证、链路追踪和健康检查配置只在启动时读取，修改后需要重启
+func watchConfig() {
+	config.Subscribe(func(e config.ChangeEvent) {
+		if !reflect.DeepEqual(e.Old.Log, e.New.Log) {
+			if err := log.Configure(e.New.Log); err != nil {
+				configLog.WithError(err).Error("failed to apply log configuration; keeping the current log outputs")
+			}
+		}
+		applyRuntimeConfig(e.New)
+
+		entry := configLog.WithFields(logrus.Fields{
+			"log_level":       e.New.Log.Level,
+			"cors_origins":    e.New.Server.CORSOrigins,
+			"request_timeout": e.New.Server.RequestTimeout.String(),
+			"rate_limit":      e.New.RateLimit.Enabled,
+		})
+		entry.Info("configuration reloaded")
+
+		if e.Old.App.Port != e.New.App.Port || e.Old.Database != e.New.Database ||
+			e.Old.Password != e.New.Password || !reflect.DeepEqual(e.Old.Auth, e.New.Auth) ||
+			e.Old.Tracing != e.New.Tracing || e.Old.Health != e.New.Health ||
+			!reflect.DeepEqual(e.Old.Server.TrustedProxies, e.New.Server.TrustedProxies) {
+			configLog.Warn("app.port, server.trustedProxies, database, password, auth, tracing and health changes take effect after a restart")
+		}
+	})
+
+	config.Watch(func(err error) {
+		configLog.WithError(err).Error("configuration reload rejected; keeping the current configuration")
+	})
+}
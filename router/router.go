@@ -2,13 +2,24 @@ package router
 
 import (
 	"context"
+	"crypto/rand"
+	"database/sql"
 	"fmt"
+	"gin-app/api/v1/admin"
+	authapi "gin-app/api/v1/auth"
 	"gin-app/api/v1/health"
 	"gin-app/api/v1/user"
+	"gin-app/buildinfo"
 	"gin-app/config"
+	"gin-app/database"
+	apperrors "gin-app/errors"
 	"gin-app/handler"
 	"gin-app/log"
+	"gin-app/metrics"
 	"gin-app/models"
+	"gin-app/ratelimit"
+	"gin-app/security"
+	"gin-app/tracing"
 	"net/http"
 	"os"
 	"os/signal"
@@ -16,6 +27,15 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// 组件日志，级别可通过管理接口单独调整
+var (
+	authLog     = log.Component("auth")
+	databaseLog = log.Component("database")
+	configLog   = log.Component("config")
 )
 
 type Route struct {
@@ -36,9 +56,9 @@ func NewGinRouter() *GinRouter {
 	}
 }
 
-// register 注册路由处理器
-func (r *GinRouter) register(method string, path string, h gin.HandlerFunc) {
-	r.engine.Handle(method, path, h)
+// register 注册路由处理器（可在处理器前附加路由级中间件）
+func (r *GinRouter) register(method string, path string, h ...gin.HandlerFunc) {
+	r.engine.Handle(method, path, h...)
 	r.routes = append(r.routes, Route{Method: method, Path: path})
 }
 
@@ -53,55 +73,205 @@ func (r *GinRouter) registerMiddleware(middleware gin.HandlerFunc) {
 	r.middlewares = append(r.middlewares, middleware)
 }
 
-// register 配置并返回Gin引擎实例
-func Register() *gin.Engine {
+// register 按cfg配置并返回Gin引擎实例和释放存储连接的关闭函数，存储等依赖的就绪检查注册到checks
+func Register(cfg *config.Config, checks *health.Registry) (*gin.Engine, func() error) {
 	r := NewGinRouter()
 
+	// 只采用可信代理转发的X-Forwarded-For，否则客户端可以伪造IP绕过按IP限流
+	if err := r.engine.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Logger.Fatalf("invalid server.trustedProxies: %v", err)
+	}
+
+	// 哨兵错误到HTTP响应的映射，显式注册而不依赖包的init
+	errorMapper := apperrors.NewMapper()
+	models.RegisterErrorMappings(errorMapper)
+
+	// 应用可热更新的配置（跨域来源、请求超时）
+	applyRuntimeConfig(*cfg)
+
 	// 注册全局中间件
-	// 顺序很重要 - 请求首先经过Logger、CORS，然后是超时检测，最后是错误处理和恢复
-	r.registerMiddleware(handler.LoggerMiddleware())                  // 记录请求日志
-	r.registerMiddleware(handler.CORSMiddleware())                    // 处理跨域请求
-	r.registerMiddleware(handler.TimeoutMiddleware(10 * time.Second)) // 10秒请求超时
-	r.registerMiddleware(handler.ErrorHandlerMiddleware())            // 统一错误处理
-	r.registerMiddleware(handler.RecoveryMiddleware())                // 从panic中恢复
+	// 顺序很重要 - 请求首先开始链路追踪span并获得请求ID，经过指标、Logger、CORS，然后是超时检测，最后是错误处理和恢复
+	r.registerMiddleware(otelgin.Middleware(cfg.App.Name))                        // 按路由模板创建请求span，沿用traceparent
+	r.registerMiddleware(handler.RequestIDMiddleware())                           // 生成或校验X-Request-ID
+	r.registerMiddleware(handler.MetricsMiddleware())                             // 按路由模板记录请求指标
+	r.registerMiddleware(handler.LoggerMiddleware())                              // 记录请求日志
+	r.registerMiddleware(handler.CORSMiddleware())                                // 处理跨域请求
+	r.registerMiddleware(handler.LocaleMiddleware())                              // 根据Accept-Language选择响应语言
+	r.registerMiddleware(handler.DynamicTimeoutMiddleware(currentRequestTimeout)) // 请求超时（server.requestTimeout）
+	r.registerMiddleware(handler.ErrorHandlerMiddleware(errorMapper))             // 统一错误处理
+	r.registerMiddleware(handler.RecoveryMiddleware())                            // 从panic中恢复
+
+	// 配置密码哈希算法
+	passwordHasher, err := newPasswordHasher(cfg.Password)
+	if err != nil {
+		log.Logger.Fatalf("failed to initialize password hasher: %v", err)
+	}
+	models.SetPasswordHasher(passwordHasher)
 
 	// 创建处理器
-	userRepo := models.NewInMemoryUserRepository()
+	userRepo, closeRepo, err := newUserRepository(cfg.Database)
+	if err != nil {
+		log.Logger.Fatalf("failed to initialize user repository: %v", err)
+	}
 	userHandler := user.NewUserHandler(userRepo)
+	if pinger, ok := userRepo.(interface{ Ping(context.Context) error }); ok {
+		checks.Register(health.NewChecker("database", pinger.Ping), health.CheckOptions{})
+	}
+
+	// 认证：JWT访问令牌 + 轮换刷新令牌
+	tokenService, err := newTokenService(cfg.Auth, userRepo)
+	if err != nil {
+		log.Logger.Fatalf("failed to initialize token service: %v", err)
+	}
+	authMiddleware := handler.AuthMiddleware(tokenService)
+
+	// 限流：规则来自rateLimit配置并随热更新替换，按用户计数时用tokenService识别调用方，
+	// 按API Key计数时只认auth.apiKeys中发放的Key
+	apiKeys, err := newAPIKeyStore(cfg.Auth)
+	if err != nil {
+		log.Logger.Fatalf("failed to initialize API keys: %v", err)
+	}
+	r.registerMiddleware(handler.RateLimitMiddleware(ratelimit.NewInMemoryStore(), tokenService, apiKeys))
+	authHandler := authapi.NewAuthHandler(userRepo, tokenService)
 
 	// 注册API路由 - v1版本 (RESTful API设计)
 	// 所有新功能应该添加在此版本下，保持向后兼容性
 	v1 := r.engine.Group("/api/v1")
 	{
 		// 健康检查和系统状态
-		health.RegisterRoutes(v1)
+		health.RegisterRoutes(v1, checks)
+
+		// 认证：登录、刷新令牌、登出
+		authHandler.RegisterRoutes(v1, authMiddleware)
 
 		// 用户管理 - RESTful设计
-		userHandler.RegisterRoutes(v1)
+		userHandler.RegisterRoutes(v1, authMiddleware)
+
+		// 运维管理：运行时调整日志级别（需要system:admin权限）
+		admin.NewAdminHandler().RegisterRoutes(v1, authMiddleware)
 	}
 
 	// 兼容旧版API（保持向后兼容性）
 	// 这些路由将继续支持，但新客户端应使用v1 API
 	// 这些路由将继续支持，但新客户端应使用v1 API
 	// 未来版本可能会考虑弃用这些端点
-	r.register("GET", "/ping", func(c *gin.Context) { health.Health(c) })
+	r.register("GET", "/ping", checks.Health)
 	r.register("GET", "/status", func(c *gin.Context) { health.Status(c) })
 
+	// 存活和就绪探针（供Kubernetes等编排系统使用）
+	r.register("GET", "/livez", checks.Livez)
+	r.register("GET", "/readyz", checks.Readyz)
+
+	// Prometheus指标（请求RED指标、Go运行时和进程指标、业务计数器）
+	r.register("GET", "/metrics", gin.WrapH(metrics.Handler()))
+
 	// Legacy user routes
-	r.register("GET", "/user/:id", userHandler.GetUser)       // 旧版本
-	r.register("POST", "/user", userHandler.CreateUser)       // 旧版本
-	r.register("DELETE", "/user/:id", userHandler.DeleteUser) // 旧版本
-	r.register("PUT", "/user/:id", userHandler.UpdateUser)    // 旧版本
-	return r.setup()
+	// 旧版本路由与v1共用相同的访问策略
+	r.register("GET", "/user/:id", authMiddleware, handler.Authorize(user.ReadUserPolicy), handler.Traced("UserHandler.GetUser", userHandler.GetUser))
+	r.register("POST", "/user", handler.Traced("UserHandler.CreateUser", userHandler.CreateUser))
+	r.register("DELETE", "/user/:id", authMiddleware, handler.Authorize(user.DeleteUserPolicy), handler.Traced("UserHandler.DeleteUser", userHandler.DeleteUser))
+	r.register("PUT", "/user/:id", authMiddleware, handler.Authorize(user.UpdateUserPolicy), handler.Traced("UserHandler.UpdateUser", userHandler.UpdateUser))
+	return r.setup(), closeRepo
+}
+
+// newUserRepository 根据配置选择用户存储后端，返回的关闭函数在优雅关闭时释放数据库连接
+func newUserRepository(cfg config.DatabaseConfig) (models.UserRepository, func() error, error) {
+	switch cfg.Driver {
+	case "sqlite":
+		db, err := database.OpenSQLite(cfg.DSN)
+		if err != nil {
+			return nil, nil, err
+		}
+		// 迁移必须在存储使用的同一连接上执行，":memory:"数据库不会在连接间共享
+		if cfg.AutoMigrate {
+			if err := migrateOnStartup(db); err != nil {
+				db.Close()
+				return nil, nil, fmt.Errorf("database migration failed: %w", err)
+			}
+		}
+		return models.NewSQLiteUserRepository(db), db.Close, nil
+	case "memory", "":
+		return models.NewInMemoryUserRepository(), func() error { return nil }, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
+	}
+}
+
+// newPasswordHasher 根据配置选择密码哈希算法
+func newPasswordHasher(cfg config.PasswordConfig) (models.PasswordHasher, error) {
+	switch cfg.Algorithm {
+	case "bcrypt", "":
+		return models.NewBcryptHasher(cfg.BcryptCost), nil
+	case "argon2id":
+		a := cfg.Argon2
+		return models.NewArgon2idHasher(a.Time, a.Memory, a.Threads, a.KeyLength, a.SaltLength), nil
+	default:
+		return nil, fmt.Errorf("unsupported password algorithm %q", cfg.Algorithm)
+	}
+}
+
+// newTokenService 根据配置创建令牌服务
+func newTokenService(cfg config.AuthConfig, userRepo models.UserRepository) (*security.TokenService, error) {
+	secret := []byte(cfg.JWTSecret)
+	if len(secret) == 0 {
+		// 未配置密钥时随机生成，重启后所有令牌失效
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+		authLog.Warn("auth.jwtSecret is not set; using a random secret, tokens will not survive restarts")
+	}
+
+	return security.NewTokenService(security.TokenOptions{
+		Secret:          secret,
+		Issuer:          cfg.Issuer,
+		AccessTokenTTL:  cfg.AccessTokenTTL,
+		RefreshTokenTTL: cfg.RefreshTokenTTL,
+	}, security.NewInMemoryRefreshTokenStore(), userRepo), nil
+}
+
+// newAPIKeyStore 根据auth.apiKeys创建API Key存储
+func newAPIKeyStore(cfg config.AuthConfig) (*security.StaticAPIKeyStore, error) {
+	digests := make(map[string]string, len(cfg.APIKeys))
+	for _, key := range cfg.APIKeys {
+		digests[key.Name] = key.SHA256
+	}
+	return security.NewStaticAPIKeyStore(digests)
 }
 
-// Serve 启动HTTP服务器并处理优雅关闭
-func Serve() {
-	router := Register()
+// migrateOnStartup 在启动时对db应用所有未执行的数据库迁移
+func migrateOnStartup(db *sql.DB) error {
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	applied, err := migrator.Up()
+	if err != nil {
+		return err
+	}
+	if applied > 0 {
+		databaseLog.Infof("applied %d database migration(s)", applied)
+	}
+	return nil
+}
+
+// Serve 按cfg启动HTTP服务器并处理优雅关闭
+func Serve(cfg *config.Config) {
+	// 链路追踪需要在创建引擎前初始化
+	shutdownTracing, err := tracing.Init(cfg.Tracing, cfg.App)
+	if err != nil {
+		log.Logger.Fatalf("failed to initialize tracing: %v", err)
+	}
+
+	checks := health.NewRegistry(cfg.Health.Timeout, cfg.Health.Interval)
+	router, closeRepo := Register(cfg, checks)
+
+	// 配置文件变化时重新加载日志、跨域和超时设置
+	watchConfig()
 
 	// 配置HTTP服务器
 	srv := &http.Server{
-		Addr:    fmt.Sprintf(":%d", config.GlobalConfig.App.Port),
+		Addr:    fmt.Sprintf(":%d", cfg.App.Port),
 		Handler: router,
 		// 添加合理的超时设置
 		ReadTimeout:  15 * time.Second,
@@ -111,11 +281,12 @@ func Serve() {
 
 	// 在一个新的goroutine中启动服务器
 	go func() {
-		appName := config.GlobalConfig.App.Name
-		appVersion := config.GlobalConfig.App.Version
-		appPort := config.GlobalConfig.App.Port
+		appName := cfg.App.Name
+		appPort := cfg.App.Port
+		build := buildinfo.Get()
 
-		log.Logger.Infof("%s v%s starting on port %d", appName, appVersion, appPort)
+		log.Logger.WithFields(logrus.Fields{"commit": build.Commit, "dirty": build.Dirty, "build_time": build.BuildTime}).
+			Infof("%s %s starting on port %d", appName, build.Version, appPort)
 		log.Logger.Infof("API v1 available at: http://localhost:%d/api/v1", appPort)
 
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -128,11 +299,22 @@ func Serve() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Logger.Info("Shutting down server...")
+	// 先让就绪探针失败，等待负载均衡摘除实例后再关闭监听
+	checks.SetShuttingDown()
+	time.Sleep(cfg.Health.ShutdownDelay)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Logger.Fatal("Server forced to shutdown:", err)
 	}
+	// 所有请求处理完成后再关闭数据库连接
+	if err := closeRepo(); err != nil {
+		log.Logger.WithError(err).Error("failed to close user repository")
+	}
+	// 导出剩余的span
+	if err := shutdownTracing(ctx); err != nil {
+		log.Logger.WithError(err).Error("failed to flush traces")
+	}
 	log.Logger.Info("Server exiting")
 }
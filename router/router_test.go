@@ -1,7 +1,18 @@
 package router
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"gin-app/api/v1/health"
+	"gin-app/config"
+	"gin-app/models"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -39,3 +50,89 @@ func TestGinRouterRegisterMiddleware(t *testing.T) {
 
 	assert.Equal(t, 1, len(router.middlewares))
 }
+
+func TestNewUserRepository(t *testing.T) {
+	repo, closeRepo, err := newUserRepository(config.DatabaseConfig{Driver: "memory"})
+	assert.Nil(t, err)
+	assert.IsType(t, &models.InMemoryUserRepository{}, repo)
+	assert.Nil(t, closeRepo())
+
+	repo, closeRepo, err = newUserRepository(config.DatabaseConfig{
+		Driver: "sqlite",
+		DSN:    filepath.Join(t.TempDir(), "users.db"),
+	})
+	assert.Nil(t, err)
+	assert.IsType(t, &models.SQLiteUserRepository{}, repo)
+
+	// 关闭后数据库连接不可再用
+	assert.Nil(t, closeRepo())
+	assert.NotNil(t, repo.(*models.SQLiteUserRepository).Ping(context.Background()))
+
+	_, _, err = newUserRepository(config.DatabaseConfig{Driver: "postgres"})
+	assert.NotNil(t, err)
+}
+
+func TestMigrateOnStartup(t *testing.T) {
+	cfg := config.DatabaseConfig{Driver: "sqlite", DSN: filepath.Join(t.TempDir(), "users.db"), AutoMigrate: true}
+	repo, closeRepo, err := newUserRepository(cfg)
+	assert.Nil(t, err)
+	assert.Nil(t, repo.Create(&models.User{ID: "1", Username: "user1", Email: "user1@example.com"}))
+	assert.Nil(t, closeRepo())
+
+	// 重复执行应当是幂等的
+	repo, closeRepo, err = newUserRepository(cfg)
+	assert.Nil(t, err)
+	assert.Nil(t, closeRepo())
+
+	// 内存数据库只存在于存储使用的连接上
+	repo, closeRepo, err = newUserRepository(config.DatabaseConfig{Driver: "sqlite", DSN: ":memory:", AutoMigrate: true})
+	assert.Nil(t, err)
+	defer closeRepo()
+	assert.Nil(t, repo.Create(&models.User{ID: "1", Username: "user1", Email: "user1@example.com"}))
+}
+
+func TestNewPasswordHasher(t *testing.T) {
+	hasher, err := newPasswordHasher(config.PasswordConfig{Algorithm: "bcrypt", BcryptCost: 4})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, hasher.(*models.BcryptHasher).Cost)
+
+	hasher, err = newPasswordHasher(config.PasswordConfig{Algorithm: "argon2id", Argon2: config.Argon2Config{Time: 1}})
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(1), hasher.(*models.Argon2idHasher).Time)
+
+	_, err = newPasswordHasher(config.PasswordConfig{Algorithm: "md5"})
+	assert.NotNil(t, err)
+}
+
+// TestForwardedForOnlyFromTrustedProxies checks that clients cannot escape the
+// per-IP login limit by sending a different X-Forwarded-For on every request
+func TestForwardedForOnlyFromTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	login := func(engine *gin.Engine, forwardedFor string) int {
+		req := httptest.NewRequest("POST", "/api/v1/auth/login", strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		resp := httptest.NewRecorder()
+		engine.ServeHTTP(resp, req)
+		return resp.Code
+	}
+
+	cfg := config.Default()
+	limit := cfg.RateLimit.Rules[0].Limit
+	engine, closeRepo := Register(&cfg, health.NewRegistry(time.Second, 0))
+	defer closeRepo()
+
+	// The peer is not a trusted proxy, so every spoofed address counts against it
+	for i := 0; i < limit; i++ {
+		assert.NotEqual(t, http.StatusTooManyRequests, login(engine, fmt.Sprintf("203.0.113.%d", i)))
+	}
+	assert.Equal(t, http.StatusTooManyRequests, login(engine, "203.0.113.250"))
+
+	// Behind a trusted proxy each forwarded client gets its own quota
+	cfg.Server.TrustedProxies = []string{"192.0.2.0/24"}
+	engine, closeRepo = Register(&cfg, health.NewRegistry(time.Second, 0))
+	defer closeRepo()
+	for i := 0; i <= limit; i++ {
+		assert.NotEqual(t, http.StatusTooManyRequests, login(engine, fmt.Sprintf("198.51.100.%d", i)))
+	}
+}
@@ -0,0 +1,42 @@
+package security
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// APIKeyStore validates API keys sent by clients
+type APIKeyStore interface {
+	// Lookup returns the name of the client owning key; ok is false for unknown keys
+	Lookup(key string) (name string, ok bool)
+}
+
+// StaticAPIKeyStore is an APIKeyStore over a fixed set of keys. Only SHA-256
+// digests of the keys are held, so configuration never contains the keys themselves
+type StaticAPIKeyStore struct {
+	names map[[sha256.Size]byte]string
+}
+
+// NewStaticAPIKeyStore creates a StaticAPIKeyStore from hex-encoded SHA-256
+// digests of the keys, indexed by client name
+func NewStaticAPIKeyStore(digests map[string]string) (*StaticAPIKeyStore, error) {
+	store := &StaticAPIKeyStore{names: make(map[[sha256.Size]byte]string, len(digests))}
+	for name, digest := range digests {
+		decoded, err := hex.DecodeString(digest)
+		if err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("API key %q: digest must be %d hex-encoded bytes", name, sha256.Size)
+		}
+		store.names[[sha256.Size]byte(decoded)] = name
+	}
+	return store, nil
+}
+
+// Lookup implements APIKeyStore
+func (s *StaticAPIKeyStore) Lookup(key string) (string, bool) {
+	if s == nil || key == "" {
+		return "", false
+	}
+	name, ok := s.names[sha256.Sum256([]byte(key))]
+	return name, ok
+}
@@ -0,0 +1,28 @@
+package security
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticAPIKeyStore(t *testing.T) {
+	sum := sha256.Sum256([]byte("secret-key"))
+	store, err := NewStaticAPIKeyStore(map[string]string{"ci": hex.EncodeToString(sum[:])})
+	assert.Nil(t, err)
+
+	name, ok := store.Lookup("secret-key")
+	assert.True(t, ok)
+	assert.Equal(t, "ci", name)
+
+	_, ok = store.Lookup("other-key")
+	assert.False(t, ok)
+	_, ok = store.Lookup("")
+	assert.False(t, ok)
+
+	// Keys given in plain text instead of as digests are rejected
+	_, err = NewStaticAPIKeyStore(map[string]string{"ci": "secret-key"})
+	assert.ErrorContains(t, err, `API key "ci"`)
+}
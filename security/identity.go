@@ -0,0 +1,41 @@
+package security
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"gin-app/models"
+)
+
+// identityKey is the gin.Context key under which the authenticated caller is stored
+const identityKey = "security.identity"
+
+// Identity describes the authenticated caller of a request
+type Identity struct {
+	UserID    string
+	Username  string
+	Roles     []string
+	TokenID   string    // jti of the access token, used for revocation
+	ExpiresAt time.Time // expiry of the access token
+}
+
+// HasPermission reports whether the caller's roles grant permission
+func (i *Identity) HasPermission(permission models.Permission) bool {
+	return models.RolesHavePermission(i.Roles, permission)
+}
+
+// SetIdentity stores the authenticated caller in the gin context
+func SetIdentity(c *gin.Context, identity *Identity) {
+	c.Set(identityKey, identity)
+}
+
+// CurrentIdentity returns the authenticated caller, if any
+func CurrentIdentity(c *gin.Context) (*Identity, bool) {
+	value, exists := c.Get(identityKey)
+	if !exists {
+		return nil, false
+	}
+	identity, ok := value.(*Identity)
+	return identity, ok
+}
@@ -0,0 +1,58 @@
+package security
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"gin-app/models"
+)
+
+// Policy decides whether an authenticated caller may perform a request
+type Policy interface {
+	Allow(c *gin.Context, identity *Identity) bool
+}
+
+// PolicyFunc adapts a function to the Policy interface
+type PolicyFunc func(c *gin.Context, identity *Identity) bool
+
+// Allow calls f(c, identity)
+func (f PolicyFunc) Allow(c *gin.Context, identity *Identity) bool {
+	return f(c, identity)
+}
+
+// Require allows callers whose roles grant all of permissions
+func Require(permissions ...models.Permission) Policy {
+	return PolicyFunc(func(c *gin.Context, identity *Identity) bool {
+		for _, p := range permissions {
+			if !identity.HasPermission(p) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Self allows callers acting on their own resource, identified by the route
+// parameter param (e.g. "id" in /users/:id)
+func Self(param string) Policy {
+	return PolicyFunc(func(c *gin.Context, identity *Identity) bool {
+		return c.Param(param) != "" && c.Param(param) == identity.UserID
+	})
+}
+
+// AnyOf allows callers satisfying at least one of policies
+func AnyOf(policies ...Policy) Policy {
+	return PolicyFunc(func(c *gin.Context, identity *Identity) bool {
+		for _, p := range policies {
+			if p.Allow(c, identity) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// SelfOr allows callers acting on their own resource, or holding permissions
+// for acting on anyone's
+func SelfOr(param string, permissions ...models.Permission) Policy {
+	return AnyOf(Self(param), Require(permissions...))
+}
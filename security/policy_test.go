@@ -0,0 +1,50 @@
+package security
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"gin-app/models"
+)
+
+func contextWithParam(id string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Params = gin.Params{{Key: "id", Value: id}}
+	return c
+}
+
+func TestRequire(t *testing.T) {
+	user := &Identity{UserID: "1", Roles: []string{models.RoleUser}}
+	admin := &Identity{UserID: "2", Roles: []string{models.RoleAdmin}}
+	c := contextWithParam("3")
+
+	policy := Require(models.PermissionUsersRead)
+	assert.False(t, policy.Allow(c, user))
+	assert.True(t, policy.Allow(c, admin))
+
+	policy = Require(models.PermissionUsersRead, models.PermissionUsersAdmin)
+	assert.False(t, policy.Allow(c, user))
+	assert.True(t, policy.Allow(c, admin))
+
+	assert.False(t, Require(models.PermissionUsersRead).Allow(c, &Identity{UserID: "4"}))
+}
+
+func TestSelfOr(t *testing.T) {
+	user := &Identity{UserID: "1", Roles: []string{models.RoleUser}}
+	admin := &Identity{UserID: "2", Roles: []string{models.RoleAdmin}}
+	policy := SelfOr("id", models.PermissionUsersWrite)
+
+	// A user may act on their own record but not on others
+	assert.True(t, policy.Allow(contextWithParam("1"), user))
+	assert.False(t, policy.Allow(contextWithParam("2"), user))
+	assert.False(t, SelfOr("id", models.PermissionUsersRead).Allow(contextWithParam("2"), user))
+
+	// Admins may act on anyone
+	assert.True(t, policy.Allow(contextWithParam("1"), admin))
+
+	// A missing parameter never matches
+	assert.False(t, Self("id").Allow(contextWithParam(""), &Identity{}))
+}
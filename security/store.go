@@ -0,0 +1,134 @@
+package security
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrRefreshTokenNotFound is returned when a refresh token is unknown or expired
+var ErrRefreshTokenNotFound = errors.New("refresh token not found")
+
+// RefreshToken is the server-side record of an issued refresh token. Only a
+// hash of the token is stored, never the token itself
+type RefreshToken struct {
+	Hash      string
+	UserID    string
+	FamilyID  string // all tokens rotated from the same login share a family
+	ExpiresAt time.Time
+	Used      bool // set once the token has been exchanged for a new pair
+}
+
+// RefreshTokenStore persists refresh tokens and access token revocations
+type RefreshTokenStore interface {
+	Save(token *RefreshToken) error
+	Get(hash string) (*RefreshToken, error)
+	// MarkUsed atomically flags a token as used and reports whether it already was
+	MarkUsed(hash string) (alreadyUsed bool, err error)
+	RevokeFamily(familyID string) error
+	// RevokeAccessToken denylists an access token ID until it expires
+	RevokeAccessToken(tokenID string, expiresAt time.Time) error
+	IsAccessTokenRevoked(tokenID string) (bool, error)
+}
+
+// InMemoryRefreshTokenStore implements RefreshTokenStore with in-memory storage
+type InMemoryRefreshTokenStore struct {
+	tokens  map[string]*RefreshToken
+	revoked map[string]time.Time
+	mutex   sync.Mutex
+	now     func() time.Time
+}
+
+// NewInMemoryRefreshTokenStore creates a new instance of InMemoryRefreshTokenStore
+func NewInMemoryRefreshTokenStore() *InMemoryRefreshTokenStore {
+	return &InMemoryRefreshTokenStore{
+		tokens:  make(map[string]*RefreshToken),
+		revoked: make(map[string]time.Time),
+		now:     time.Now,
+	}
+}
+
+// Save stores a refresh token and drops expired entries
+func (s *InMemoryRefreshTokenStore) Save(token *RefreshToken) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.purgeExpired()
+	stored := *token
+	s.tokens[token.Hash] = &stored
+	return nil
+}
+
+// Get retrieves a refresh token by hash
+func (s *InMemoryRefreshTokenStore) Get(hash string) (*RefreshToken, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	token, exists := s.tokens[hash]
+	if !exists || !s.now().Before(token.ExpiresAt) {
+		return nil, ErrRefreshTokenNotFound
+	}
+	copied := *token
+	return &copied, nil
+}
+
+// MarkUsed flags a refresh token as used
+func (s *InMemoryRefreshTokenStore) MarkUsed(hash string) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	token, exists := s.tokens[hash]
+	if !exists {
+		return false, ErrRefreshTokenNotFound
+	}
+	alreadyUsed := token.Used
+	token.Used = true
+	return alreadyUsed, nil
+}
+
+// RevokeFamily removes every refresh token in a family
+func (s *InMemoryRefreshTokenStore) RevokeFamily(familyID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for hash, token := range s.tokens {
+		if token.FamilyID == familyID {
+			delete(s.tokens, hash)
+		}
+	}
+	return nil
+}
+
+// RevokeAccessToken denylists an access token until it expires
+func (s *InMemoryRefreshTokenStore) RevokeAccessToken(tokenID string, expiresAt time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.purgeExpired()
+	s.revoked[tokenID] = expiresAt
+	return nil
+}
+
+// IsAccessTokenRevoked reports whether an access token has been revoked
+func (s *InMemoryRefreshTokenStore) IsAccessTokenRevoked(tokenID string) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, revoked := s.revoked[tokenID]
+	return revoked, nil
+}
+
+// purgeExpired drops expired refresh tokens and revocations; callers must hold the mutex
+func (s *InMemoryRefreshTokenStore) purgeExpired() {
+	now := s.now()
+	for hash, token := range s.tokens {
+		if !now.Before(token.ExpiresAt) {
+			delete(s.tokens, hash)
+		}
+	}
+	for id, expiresAt := range s.revoked {
+		if !now.Before(expiresAt) {
+			delete(s.revoked, id)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package security
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"gin-app/models"
+)
+
+var (
+	// ErrInvalidToken is returned for malformed, expired or revoked tokens
+	ErrInvalidToken = errors.New("invalid or expired token")
+	// ErrRefreshTokenReused is returned when an already rotated refresh token is
+	// presented again; the whole token family is revoked as a precaution
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+)
+
+// TokenOptions configures a TokenService
+type TokenOptions struct {
+	Secret          []byte
+	Issuer          string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+// Claims are the JWT claims carried by access tokens
+type Claims struct {
+	Username string   `json:"username"`
+	Roles    []string `json:"roles,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// TokenPair is returned to clients on login and refresh
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"` // access token lifetime in seconds
+}
+
+// TokenService issues short-lived JWT access tokens and rotating opaque refresh tokens
+type TokenService struct {
+	opts  TokenOptions
+	store RefreshTokenStore
+	users models.UserRepository
+	now   func() time.Time
+}
+
+// NewTokenService creates a TokenService. users is consulted on refresh so that
+// deleted users cannot keep renewing their session
+func NewTokenService(opts TokenOptions, store RefreshTokenStore, users models.UserRepository) *TokenService {
+	return &TokenService{
+		opts:  opts,
+		store: store,
+		users: users,
+		now:   time.Now,
+	}
+}
+
+// Issue creates a new token pair for user, starting a new refresh token family
+func (s *TokenService) Issue(user *models.User) (*TokenPair, error) {
+	return s.issue(user, uuid.New().String())
+}
+
+// ParseAccessToken validates an access token and returns its claims
+func (s *TokenService) ParseAccessToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return s.opts.Secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(s.opts.Issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(s.now),
+	)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	revoked, err := s.store.IsAccessTokenRevoked(claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// Refresh exchanges a refresh token for a new pair. Each refresh token can be
+// used once; presenting a used token revokes every token in its family
+func (s *TokenService) Refresh(refreshToken string) (*TokenPair, error) {
+	hash := hashToken(refreshToken)
+	stored, err := s.store.Get(hash)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	alreadyUsed, err := s.store.MarkUsed(hash)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if alreadyUsed {
+		if err := s.store.RevokeFamily(stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	user, err := s.users.GetByID(stored.UserID)
+	if err != nil {
+		_ = s.store.RevokeFamily(stored.FamilyID)
+		return nil, ErrInvalidToken
+	}
+	return s.issue(user, stored.FamilyID)
+}
+
+// Revoke invalidates the caller's access token and, if given, the refresh
+// token family it belongs to. Refresh tokens owned by another user are ignored
+func (s *TokenService) Revoke(identity *Identity, refreshToken string) error {
+	if err := s.store.RevokeAccessToken(identity.TokenID, identity.ExpiresAt); err != nil {
+		return err
+	}
+	if refreshToken == "" {
+		return nil
+	}
+
+	stored, err := s.store.Get(hashToken(refreshToken))
+	if err != nil || stored.UserID != identity.UserID {
+		return nil
+	}
+	return s.store.RevokeFamily(stored.FamilyID)
+}
+
+func (s *TokenService) issue(user *models.User, familyID string) (*TokenPair, error) {
+	now := s.now()
+	claims := Claims{
+		Username: user.Username,
+		Roles:    user.Roles,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Subject:   user.ID,
+			Issuer:    s.opts.Issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.opts.AccessTokenTTL)),
+		},
+	}
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.opts.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	err = s.store.Save(&RefreshToken{
+		Hash:      hashToken(refreshToken),
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		ExpiresAt: now.Add(s.opts.RefreshTokenTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.opts.AccessTokenTTL / time.Second),
+	}, nil
+}
+
+// randomToken returns 32 bytes of randomness encoded for use in URLs and JSON
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
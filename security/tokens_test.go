@@ -0,0 +1,101 @@
+package security
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"gin-app/models"
+)
+
+func newTestTokenService(t *testing.T) (*TokenService, *models.User) {
+	repo := models.NewInMemoryUserRepository()
+	user := &models.User{ID: "1", Username: "testuser", Email: "test@example.com"}
+	assert.Nil(t, repo.Create(user))
+
+	tokens := NewTokenService(TokenOptions{
+		Secret:          []byte("test-secret"),
+		Issuer:          "gin-app-test",
+		AccessTokenTTL:  time.Minute,
+		RefreshTokenTTL: time.Hour,
+	}, NewInMemoryRefreshTokenStore(), repo)
+	return tokens, user
+}
+
+func TestIssueAndParseAccessToken(t *testing.T) {
+	tokens, user := newTestTokenService(t)
+
+	pair, err := tokens.Issue(user)
+	assert.Nil(t, err)
+	assert.Equal(t, "Bearer", pair.TokenType)
+	assert.Equal(t, int64(60), pair.ExpiresIn)
+	assert.NotEmpty(t, pair.RefreshToken)
+
+	claims, err := tokens.ParseAccessToken(pair.AccessToken)
+	assert.Nil(t, err)
+	assert.Equal(t, "1", claims.Subject)
+	assert.Equal(t, "testuser", claims.Username)
+
+	// Tampered, foreign and expired tokens are rejected
+	_, err = tokens.ParseAccessToken(pair.AccessToken + "x")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	other := NewTokenService(TokenOptions{Secret: []byte("other"), Issuer: "gin-app-test", AccessTokenTTL: time.Minute}, NewInMemoryRefreshTokenStore(), nil)
+	_, err = other.ParseAccessToken(pair.AccessToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	tokens.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	_, err = tokens.ParseAccessToken(pair.AccessToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestRefreshRotatesAndDetectsReuse(t *testing.T) {
+	tokens, user := newTestTokenService(t)
+
+	first, err := tokens.Issue(user)
+	assert.Nil(t, err)
+
+	second, err := tokens.Refresh(first.RefreshToken)
+	assert.Nil(t, err)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+
+	// Reusing the rotated token revokes the whole family, including the new token
+	_, err = tokens.Refresh(first.RefreshToken)
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+
+	_, err = tokens.Refresh(second.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = tokens.Refresh("unknown")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestRevoke(t *testing.T) {
+	tokens, user := newTestTokenService(t)
+
+	pair, err := tokens.Issue(user)
+	assert.Nil(t, err)
+	claims, err := tokens.ParseAccessToken(pair.AccessToken)
+	assert.Nil(t, err)
+
+	identity := &Identity{UserID: claims.Subject, TokenID: claims.ID, ExpiresAt: claims.ExpiresAt.Time}
+	assert.Nil(t, tokens.Revoke(identity, pair.RefreshToken))
+
+	_, err = tokens.ParseAccessToken(pair.AccessToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = tokens.Refresh(pair.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestRefreshFailsForDeletedUser(t *testing.T) {
+	tokens, user := newTestTokenService(t)
+
+	pair, err := tokens.Issue(user)
+	assert.Nil(t, err)
+	assert.Nil(t, tokens.users.Delete(user.ID))
+
+	_, err = tokens.Refresh(pair.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	apperrors "gin-app/errors"
 	"gin-app/handler"
 
 	"github.com/gin-gonic/gin"
@@ -15,7 +16,7 @@ import (
 
 func setupTestRouter() *gin.Engine {
 	router := gin.Default()
-	router.Use(handler.ErrorHandlerMiddleware())
+	router.Use(handler.ErrorHandlerMiddleware(apperrors.NewMapper()))
 	router.Use(handler.RecoveryMiddleware())
 	router.Use(handler.LoggerMiddleware())
 	router.Use(handler.CORSMiddleware())
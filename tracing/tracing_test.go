@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+
+	"gin-app/config"
+)
+
+func TestInitFileExporter(t *testing.T) {
+	cfg := config.Default()
+	cfg.Tracing.Exporter = "file"
+	cfg.Tracing.Filename = filepath.Join(t.TempDir(), "traces.json")
+
+	shutdown, err := Init(cfg.Tracing, cfg.App)
+	assert.Nil(t, err)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "test-span")
+	assert.True(t, span.SpanContext().IsValid())
+	span.End()
+	assert.Nil(t, shutdown(context.Background()))
+
+	data, err := os.ReadFile(cfg.Tracing.Filename)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"Name":"test-span"`)
+	assert.Contains(t, string(data), `"Value":"gin-app"`) // service.name
+}
+
+func TestInitDisabled(t *testing.T) {
+	cfg := config.Default()
+	shutdown, err := Init(cfg.Tracing, cfg.App)
+	assert.Nil(t, err)
+	assert.Nil(t, shutdown(context.Background()))
+
+	_, span := otel.Tracer("test").Start(context.Background(), "test-span")
+	assert.False(t, span.IsRecording())
+}
+
+func TestInitSampleRatio(t *testing.T) {
+	cfg := config.Default()
+	cfg.Tracing.Exporter = "stdout"
+	cfg.Tracing.SampleRatio = 0
+
+	shutdown, err := Init(cfg.Tracing, cfg.App)
+	assert.Nil(t, err)
+	defer shutdown(context.Background())
+
+	_, span := otel.Tracer("test").Start(context.Background(), "test-span")
+	assert.False(t, span.SpanContext().IsSampled())
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"gin-app/buildinfo"
+)
+
+// runVersion 输出构建信息和编译进二进制的依赖模块，不读取配置
+func runVersion(w io.Writer) error {
+	info := buildinfo.Get()
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "version\t%s\n", info.Version)
+	if info.Commit != "" {
+		commit := info.Commit
+		if info.Dirty {
+			commit += " (dirty)"
+		}
+		fmt.Fprintf(tw, "commit\t%s\n", commit)
+	}
+	if info.CommitTime != "" {
+		fmt.Fprintf(tw, "commit time\t%s\n", info.CommitTime)
+	}
+	if info.BuildTime != "" {
+		fmt.Fprintf(tw, "build time\t%s\n", info.BuildTime)
+	}
+	fmt.Fprintf(tw, "go\t%s\n", info.GoVersion)
+	if info.Module != "" {
+		fmt.Fprintf(tw, "module\t%s\n", info.Module)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(info.Deps) == 0 {
+		return nil
+	}
+	fmt.Fprintln(w, "\ndependencies:")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, dep := range info.Deps {
+		if dep.Replace != "" {
+			fmt.Fprintf(tw, "  %s\t%s\t=> %s\n", dep.Path, dep.Version, dep.Replace)
+		} else {
+			fmt.Fprintf(tw, "  %s\t%s\n", dep.Path, dep.Version)
+		}
+	}
+	return tw.Flush()
+}